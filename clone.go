@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/cli/safeexec"
+)
+
+// runClone implements "gh list-repos clone owner/name [owner/name...]" and returns the exit code
+func runClone(args []string) int {
+	fs := flag.NewFlagSet("clone", flag.ExitOnError)
+	dirPtr := fs.String("dir", "", "Base directory to clone the repositories into (default current directory)")
+	barePtr := fs.Bool("bare", false, "Create bare clones (passed through to git clone --bare)")
+	depthPtr := fs.Int("depth", 0, "Create shallow clones with the given number of commits (passed through to git clone --depth)")
+	archivedDepthPtr := fs.Int("archived-depth", 1, "Clone depth of archived repositories when -depth isn't set, their history no longer changes (0 clones the full history)")
+	urlTypePtr := fs.String("url-type", "https", "URL used to clone: https or ssh")
+	tokenPtr := fs.String("token", "", "GitHub token used instead of the gh authentication (default GH_TOKEN or GITHUB_TOKEN)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gh list-repos clone [-dir <path>] [-bare] [-depth <n>] [-archived-depth <n>] [-url-type <https|ssh>] <owner/name>...")
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	urlType := *urlTypePtr
+	if urlType != "https" && urlType != "ssh" {
+		fmt.Fprintf(os.Stderr, "Invalid -url-type %q, expected https or ssh\n", urlType)
+		return 1
+	}
+
+	token := *tokenPtr
+	if token == "" {
+		token = github.TokenFromEnv()
+	}
+
+	client, err := github.NewClient(github.ClientOptions{AuthToken: token})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
+		return 1
+	}
+
+	gitPath, err := safeexec.LookPath("git")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "git not found in PATH: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+	for _, arg := range fs.Args() {
+		// accept whole lines selected in fzf, the repository is always the first field
+		fields := strings.Fields(arg)
+		if len(fields) == 0 {
+			continue
+		}
+		nameWithOwner := fields[0]
+
+		if err := cloneRepository(client, gitPath, nameWithOwner, *dirPtr, *barePtr, *depthPtr, *archivedDepthPtr, urlType); err != nil {
+			log.Printf("Error cloning %s: %v", nameWithOwner, err)
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", nameWithOwner, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "✓ %s\n", nameWithOwner)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d repositories failed to clone\n", failed, fs.NArg())
+		return 1
+	}
+
+	return 0
+}
+
+func cloneRepository(client github.GraphQLClient, gitPath string, nameWithOwner string, dir string, bare bool, depth int, archivedDepth int, urlType string) error {
+	repo, err := github.GetRepository(client, nameWithOwner)
+	if err != nil {
+		return err
+	}
+
+	// archived repositories are read-only but still cloneable
+	if repo.IsArchived {
+		fmt.Fprintf(os.Stderr, "! %s is archived (read-only)\n", repo.NameWithOwner)
+		if depth == 0 {
+			depth = archivedDepth
+		}
+	}
+
+	// use the name returned by the API (casing, renames) for the destination directory
+	_, name, _ := strings.Cut(repo.NameWithOwner, "/")
+	if bare {
+		name += ".git"
+	}
+
+	gitArgs := []string{"clone"}
+	if bare {
+		gitArgs = append(gitArgs, "--bare")
+	}
+	if depth > 0 {
+		gitArgs = append(gitArgs, "--depth", strconv.Itoa(depth))
+	}
+	gitArgs = append(gitArgs, repo.CloneURL(urlType), filepath.Join(dir, name))
+
+	log.Printf("[%s]: running git %s", nameWithOwner, strings.Join(gitArgs, " "))
+
+	cmd := exec.Command(gitPath, gitArgs...)
+	// keep stdout clean, git reports its progress on stderr anyway
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// fetchSources fetches the repositories of every source concurrently and streams
+// them to the returned channel, which is closed once all sources are done.
+// The error of a failed source is passed to onError, the other sources continue.
+// Up to buffer results are queued when the consumer is slower than the producers.
+// With notifyDone every source ends with a Done result once all its repositories were sent.
+func fetchSources(sources []github.Source, opts github.Options, buffer int, notifyDone bool, onError func(source github.Source, err error)) <-chan github.Result {
+	// Channel to send repositories (and the source they come from) to, the producers
+	// go through a shared emitter counting them and enforcing the limit
+	resultChannel := make(chan github.Result, buffer)
+	emitter := github.NewEmitter(resultChannel, opts.Limit)
+
+	// One round trip for the first page of several organizations, the rest is paginated per source
+	if opts.BatchOrgs {
+		var orgs []string
+		for _, source := range sources {
+			if source.Kind == github.SourceOrg {
+				orgs = append(orgs, source.Login)
+			}
+		}
+
+		if len(orgs) > 1 {
+			firstPages, err := github.FetchOrgFirstPages(orgs, opts)
+			if err != nil {
+				log.Printf("Warning: fetching the organizations in batches: %v", err)
+			}
+			opts.FirstPages = firstPages
+		}
+	}
+
+	// Wait group for user and organization fetches to run in parallel
+	var fetchWG sync.WaitGroup
+
+	// Sources wait for a slot in order, so with a single slot they are fetched one after the other.
+	// The slots are taken by a goroutine of their own so the consumer can start reading right away.
+	var slots chan struct{}
+	if opts.MaxConcurrency > 0 {
+		slots = make(chan struct{}, opts.MaxConcurrency)
+	}
+
+	fetchWG.Add(len(sources))
+
+	go func() {
+		for _, source := range sources {
+			if slots != nil {
+				slots <- struct{}{}
+			}
+
+			// Launch new goroutine for each source
+			go func(currentSource github.Source) {
+				// Decrement fetch wg when this source goroutine finishes
+				defer fetchWG.Done()
+				if slots != nil {
+					defer func() { <-slots }()
+				}
+
+				// Deferred before the recover so it runs after it, a panicking source is done too.
+				// Sent directly rather than through the emitter, it doesn't count against the limit.
+				if notifyDone {
+					defer func() { resultChannel <- github.Result{Source: currentSource, Done: true} }()
+				}
+
+				// A panic (e.g. an unexpected nil in a response) only fails its own source
+				defer func() {
+					if value := recover(); value != nil {
+						onError(currentSource, github.PanicError(currentSource, value))
+					}
+				}()
+
+				// every source counts against its own -limit-per-source and the shared -limit
+				emitter := emitter.WithLimit(opts.LimitPerSource)
+
+				var err error
+				switch currentSource.Kind {
+				case github.SourceUser:
+					err = github.ProcessUserRepositories(currentSource.Login, opts, emitter)
+				case github.SourceOrg:
+					err = github.ProcessOrgRepositories(currentSource.Login, opts, emitter)
+				case github.SourceOwner:
+					err = github.ProcessOwnerRepositories(currentSource.Login, opts, emitter)
+				case github.SourceNames:
+					err = github.ProcessRepositoryNames(currentSource, opts, emitter)
+				}
+
+				if err != nil {
+					onError(currentSource, err)
+				}
+				// Pass the current source value to the goroutine
+			}(source)
+		}
+	}()
+
+	// Goroutine to close the channel when all data source workers are done
+	go func() {
+		// Wait for all user and org goroutines to complete
+		fetchWG.Wait()
+		close(resultChannel)
+	}()
+
+	return resultChannel
+}
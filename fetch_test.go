@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/output"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// TestMain keeps the logs of the producers out of the test output
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// orgClient answers the organization queries with a single page of repositories, except for
+// the logins in block, blocking until the query is cancelled, and in broken, panicking.
+// With jitter every query takes a random time up to it, so the sources finish in any order.
+type orgClient struct {
+	repos  int
+	block  map[string]bool
+	broken map[string]bool
+	jitter time.Duration
+}
+
+func (c orgClient) Query(name string, q any, variables map[string]any) error {
+	return c.QueryWithContext(context.Background(), name, q, variables)
+}
+
+func (c orgClient) QueryWithContext(ctx context.Context, name string, q any, variables map[string]any) error {
+	query, ok := q.(*github.GetOrgRepositoriesQuery)
+	if !ok {
+		return fmt.Errorf("unexpected query %s (%T)", name, q)
+	}
+
+	org := string(variables["org"].(graphql.String))
+	if c.jitter > 0 {
+		time.Sleep(rand.N(c.jitter))
+	}
+
+	if c.block[org] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if c.broken[org] {
+		// an unexpected nil in a response
+		var owner *github.RepositoryOwner
+		_ = owner.Login
+	}
+
+	for i := range c.repos {
+		query.Organization.Repositories.Nodes = append(query.Organization.Repositories.Nodes, github.Repository{NameWithOwner: fmt.Sprintf("%s/repo-%d", org, i)})
+	}
+	query.Organization.Repositories.TotalCount = c.repos
+
+	return nil
+}
+
+// pagedClient answers the first page of the organizations with repos repositories and
+// more pages to come, then panics on the second page like a response with an unexpected nil
+type pagedClient struct {
+	repos int
+}
+
+func (c pagedClient) Query(name string, q any, variables map[string]any) error {
+	return c.QueryWithContext(context.Background(), name, q, variables)
+}
+
+func (c pagedClient) QueryWithContext(ctx context.Context, name string, q any, variables map[string]any) error {
+	query, ok := q.(*github.GetOrgRepositoriesQuery)
+	if !ok {
+		return fmt.Errorf("unexpected query %s (%T)", name, q)
+	}
+
+	if cursor, _ := variables["cursor"].(graphql.String); cursor != "" {
+		var owner *github.RepositoryOwner
+		_ = owner.Login
+	}
+
+	org := string(variables["org"].(graphql.String))
+	for i := range c.repos {
+		query.Organization.Repositories.Nodes = append(query.Organization.Repositories.Nodes, github.Repository{NameWithOwner: fmt.Sprintf("%s/repo-%d", org, i)})
+	}
+	query.Organization.Repositories.TotalCount = 2 * c.repos
+	query.Organization.Repositories.PageInfo.HasNextPage = true
+	query.Organization.Repositories.PageInfo.EndCursor = "page-2"
+
+	return nil
+}
+
+// fetchAll runs fetchSources over the organizations and returns the number of repositories
+// and the error of every source. Every source must end with its Done result.
+func fetchAll(t *testing.T, orgs []string, opts github.Options) (map[string]int, map[string]error) {
+	t.Helper()
+
+	var sources []github.Source
+	for _, org := range orgs {
+		sources = append(sources, github.Source{Kind: github.SourceOrg, Login: org})
+	}
+
+	var mu sync.Mutex
+	failures := make(map[string]error)
+	results := fetchSources(sources, opts, 0, true, func(source github.Source, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures[source.Login] = err
+	})
+
+	counts := make(map[string]int)
+	done := make(map[string]bool)
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				for _, org := range orgs {
+					if !done[org] {
+						t.Errorf("%s never sent its Done result", org)
+					}
+				}
+				return counts, failures
+			}
+
+			if result.Done {
+				done[result.Source.Login] = true
+				continue
+			}
+			counts[result.Source.Login]++
+		case <-timeout:
+			t.Fatal("the sources didn't finish")
+		}
+	}
+}
+
+func TestSourceTimeoutOnlyFailsItsSource(t *testing.T) {
+	client := orgClient{repos: 20, block: map[string]bool{"slow": true}}
+	opts := github.Options{Client: client, SourceTimeout: 50 * time.Millisecond}
+
+	counts, failures := fetchAll(t, []string{"acme", "slow", "tiny"}, opts)
+
+	if !errors.Is(failures["slow"], github.ErrSourceTimeout) {
+		t.Errorf("slow source failed with %v, want %v", failures["slow"], github.ErrSourceTimeout)
+	}
+
+	for _, org := range []string{"acme", "tiny"} {
+		if counts[org] != 20 || failures[org] != nil {
+			t.Errorf("%s: %d repositories and error %v, want 20 and no error", org, counts[org], failures[org])
+		}
+	}
+}
+
+func TestPanicOnlyFailsItsSource(t *testing.T) {
+	client := orgClient{repos: 20, broken: map[string]bool{"broken": true}}
+
+	counts, failures := fetchAll(t, []string{"acme", "broken", "tiny"}, github.Options{Client: client})
+
+	if !errors.Is(failures["broken"], github.ErrSourcePanic) {
+		t.Errorf("broken source failed with %v, want %v", failures["broken"], github.ErrSourcePanic)
+	}
+
+	for _, org := range []string{"acme", "tiny"} {
+		if counts[org] != 20 || failures[org] != nil {
+			t.Errorf("%s: %d repositories and error %v, want 20 and no error", org, counts[org], failures[org])
+		}
+	}
+}
+
+func TestDeterministicOutputAcrossRuns(t *testing.T) {
+	client := orgClient{repos: 30, jitter: 5 * time.Millisecond}
+	orgs := []string{"acme", "beta", "tiny", "zeta", "omega"}
+
+	// the sources are fetched concurrently and finish in a random order, sorting by name
+	// alone must print the same output on every run
+	render := func() string {
+		var buf strings.Builder
+		line, err := output.New("line", &buf, output.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		writer, err := output.NewSortWriter(line, "name", false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sources []github.Source
+		for _, org := range orgs {
+			sources = append(sources, github.Source{Kind: github.SourceOrg, Login: org})
+		}
+
+		for result := range fetchSources(sources, github.Options{Client: client}, 0, false, func(source github.Source, err error) {
+			t.Errorf("%s failed: %v", source, err)
+		}) {
+			if err := writer.Write(result); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	first := render()
+	if lines := strings.Count(first, "\n"); lines != len(orgs)*30 {
+		t.Fatalf("printed %d lines, want %d", lines, len(orgs)*30)
+	}
+
+	for run := 2; run <= 5; run++ {
+		if got := render(); got != first {
+			t.Fatalf("run %d printed a different output", run)
+		}
+	}
+}
+
+func TestPanicWhileEmittingThePreviousPage(t *testing.T) {
+	opts := github.Options{Client: pagedClient{repos: 5}, PageSize: 5}
+	sources := []github.Source{{Kind: github.SourceOrg, Login: "acme"}}
+
+	var failure error
+	results := fetchSources(sources, opts, 0, true, func(source github.Source, err error) {
+		failure = err
+	})
+
+	// a slow reader keeps the first page being emitted while the second one panics
+	var received []github.Result
+	for result := range results {
+		time.Sleep(10 * time.Millisecond)
+		received = append(received, result)
+	}
+
+	if !errors.Is(failure, github.ErrSourcePanic) {
+		t.Errorf("source failed with %v, want %v", failure, github.ErrSourcePanic)
+	}
+
+	if len(received) != 6 || !received[5].Done {
+		t.Fatalf("received %d results, want the 5 repositories of the first page followed by the Done result", len(received))
+	}
+
+	for i, result := range received[:5] {
+		if want := fmt.Sprintf("acme/repo-%d", i); result.Done || result.Repository.NameWithOwner != want {
+			t.Errorf("result %d is %+v, want %s", i, result, want)
+		}
+	}
+}
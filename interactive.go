@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// interactivePageSize is the number of repositories listed at once by the menu
+const interactivePageSize = 20
+
+// interactiveWriter collects the repositories and lets the user pick one from a
+// numbered menu on Flush, a minimal fallback for terminals without fzf
+type interactiveWriter struct {
+	in       io.Reader
+	menu     io.Writer
+	out      io.Writer
+	lineOpts github.LineOptions
+	repos    []github.Repository
+}
+
+func (iw *interactiveWriter) Write(result github.Result) error {
+	iw.repos = append(iw.repos, result.Repository)
+	return nil
+}
+
+// Flush runs the menu: a number picks the repository and prints its "owner/name" to out,
+// any other text filters the list, "n" shows the next repositories and an empty line quits
+func (iw *interactiveWriter) Flush() error {
+	scanner := bufio.NewScanner(iw.in)
+	matches := iw.repos
+	offset := 0
+
+	for {
+		if len(matches) == 0 {
+			fmt.Fprintln(iw.menu, "No repositories match, type another filter")
+		}
+
+		end := min(offset+interactivePageSize, len(matches))
+		for i := offset; i < end; i++ {
+			fmt.Fprintf(iw.menu, "%4d  %s\n", i+1, matches[i].Line(iw.lineOpts))
+		}
+
+		if end < len(matches) {
+			fmt.Fprintf(iw.menu, "      ... %d more, \"n\" for the next ones\n", len(matches)-end)
+		}
+
+		fmt.Fprint(iw.menu, "Select a repository (number, text to filter, empty to quit): ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(iw.menu)
+			return scanner.Err()
+		}
+
+		input := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case input == "":
+			return nil
+		case input == "n":
+			if end < len(matches) {
+				offset = end
+			}
+		default:
+			if number, err := strconv.Atoi(input); err == nil {
+				if number < 1 || number > len(matches) {
+					fmt.Fprintf(iw.menu, "%d is not in the list\n", number)
+					continue
+				}
+
+				_, err := fmt.Fprintln(iw.out, matches[number-1].NameWithOwner)
+				return err
+			}
+
+			matches = filterRepositories(iw.repos, input)
+			offset = 0
+		}
+	}
+}
+
+// filterRepositories returns the repositories whose line contains text, ignoring case
+func filterRepositories(repos []github.Repository, text string) []github.Repository {
+	text = strings.ToLower(text)
+
+	var matches []github.Repository
+	for _, repo := range repos {
+		if strings.Contains(strings.ToLower(repo.Line(github.LineOptions{})), text) {
+			matches = append(matches, repo)
+		}
+	}
+
+	return matches
+}
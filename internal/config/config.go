@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath returns the location of the config file used when -config is not provided
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".config", "gh-list-repos", "config.yaml"), nil
+}
+
+// Load reads a YAML config file whose keys are flag names (e.g. "no-fork: true").
+// A missing file is not an error, it simply yields no values.
+func Load(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			// an empty key leaves the flag default untouched
+		case []any:
+			// lists are accepted for comma-separated flags like "orgs"
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case map[string]any:
+			return nil, fmt.Errorf("parsing %s: %q must be a scalar or a list", path, key)
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+
+	return values, nil
+}
+
+// EnvPrefix is the prefix of the environment variables read by FromEnv
+const EnvPrefix = "GH_LIST_REPOS_"
+
+// EnvName returns the environment variable of a flag: EnvPrefix followed by the
+// uppercased flag name with dashes turned into underscores (no-fork is GH_LIST_REPOS_NO_FORK)
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// FromEnv returns the values of the flags set through environment variables, see EnvName.
+// Empty variables are ignored. The values are meant to be passed to Apply.
+func FromEnv(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if value := os.Getenv(EnvName(f.Name)); value != "" {
+			values[f.Name] = value
+		}
+	})
+
+	return values
+}
+
+// Apply sets the given values on the flag set, skipping the flags that were
+// explicitly passed on the command line so they always win over the file.
+func Apply(fs *flag.FlagSet, values map[string]string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for name, value := range values {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown flag %q", name)
+		}
+
+		if explicit[name] {
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value %q for flag %q: %w", value, name, err)
+		}
+	}
+
+	return nil
+}
+
+// secretFlags are the flags whose value is never written by Save, headers may carry credentials too
+var secretFlags = []string{"token", "header"}
+
+// Save writes the effective value of every flag of fs (command line, environment, config file
+// or default) to path as a JSON object keyed by flag name, leaving out the secretFlags
+func Save(fs *flag.FlagSet, path string) error {
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if !slices.Contains(secretFlags, f.Name) {
+			values[f.Name] = f.Value.String()
+		}
+	})
+
+	// encoding/json sorts the keys, so saved queries diff nicely
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// LoadSources reads newline-separated sources from a file. Each line is either
+// "user:<login>", "org:<login>" or a bare login which is treated as an organization.
+// Blank lines and lines starting with "#" are skipped.
+func LoadSources(path string) ([]github.Source, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var sources []github.Source
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		source := github.Source{Kind: github.SourceOrg, Login: line}
+		if kind, login, found := strings.Cut(line, ":"); found {
+			switch github.SourceKind(strings.TrimSpace(kind)) {
+			case github.SourceUser:
+				source.Kind = github.SourceUser
+			case github.SourceOrg:
+				source.Kind = github.SourceOrg
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown source type %q", path, lineNumber, kind)
+			}
+			source.Login = strings.TrimSpace(login)
+		}
+
+		if source.Login == "" {
+			return nil, fmt.Errorf("%s:%d: missing login", path, lineNumber)
+		}
+
+		sources = append(sources, source)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// ReadNames reads "owner/name" lines, e.g. a list printed by a previous run. Only the first
+// field of every line is used, so the aligned lines of the line format can be read back.
+// Blank lines and lines starting with "#" are skipped.
+func ReadNames(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		owner, name, found := strings.Cut(fields[0], "/")
+		if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("line %d: invalid repository %q, expected owner/name", lineNumber, fields[0])
+		}
+
+		names = append(names, fields[0])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
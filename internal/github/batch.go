@@ -0,0 +1,107 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// OrgBatchSize is the number of organizations whose first page is requested in a single query.
+// The cost of a query grows with every aliased connection, so bigger batches hit the node and
+// complexity limits of the API sooner (and a slow organization delays all the others).
+const OrgBatchSize = 10
+
+// FirstPages holds the first page of the organizations fetched by FetchOrgFirstPages.
+// It is only written before the producers start, then only read.
+type FirstPages struct {
+	pages map[string]Repositories
+}
+
+// get returns the prefetched first page of the source, organizations only
+func (f *FirstPages) get(source Source) (Repositories, bool) {
+	if f == nil || source.Kind != SourceOrg {
+		return Repositories{}, false
+	}
+
+	page, found := f.pages[strings.ToLower(source.Login)]
+	return page, found
+}
+
+// FetchOrgFirstPages fetches the first page of every organization with one query per OrgBatchSize
+// organizations, mapping the aliased fields ("o0: organization(login: $org0)", ...) back to each login.
+// Organizations missing from the result (not found, failed batch) are left to the regular
+// pagination, which fetches their first page again and reports their error.
+func FetchOrgFirstPages(orgs []string, opts Options) (*FirstPages, error) {
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
+
+	firstPages := &FirstPages{pages: map[string]Repositories{}}
+
+	for start := 0; start < len(orgs); start += OrgBatchSize {
+		batch := orgs[start:min(start+OrgBatchSize, len(orgs))]
+
+		if err := fetchOrgBatch(client, batch, opts, firstPages); err != nil {
+			log.Printf("Warning: batch of %d organizations: %v, the missing ones are fetched one by one\n", len(batch), err)
+		}
+	}
+
+	return firstPages, nil
+}
+
+// organizationType is the type of the organization field of GetOrgRepositoriesQuery, reused by the aliased fields
+var organizationType = func() reflect.Type {
+	field, _ := reflect.TypeOf(GetOrgRepositoriesQuery{}).FieldByName("Organization")
+	return field.Type
+}()
+
+// fetchOrgBatch fetches the first page of the organizations of a batch into firstPages
+func fetchOrgBatch(client GraphQLClient, orgs []string, opts Options, firstPages *FirstPages) error {
+	// the query is built at runtime as the number of aliased fields depends on the batch
+	fields := []reflect.StructField{{Name: "RateLimit", Type: reflect.TypeOf(RateLimit{})}}
+	variables := opts.repositoriesVariables()
+
+	for i, org := range orgs {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("O%d", i),
+			// a pointer so organizations that can't be resolved are left nil
+			Type: reflect.PointerTo(organizationType),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"o%d: organization(login: $org%d)"`, i, i)),
+		})
+		variables[fmt.Sprintf("org%d", i)] = graphql.String(org)
+	}
+
+	query := reflect.New(reflect.StructOf(fields))
+
+	log.Printf("getting the first page of %d organizations in a batch...\n", len(orgs))
+
+	// a GraphQL error (e.g. one organization not found) still returns the data of the others
+	err := client.Query("GetOrgFirstPages", query.Interface(), variables)
+	var graphQLErr *api.GraphQLError
+	if err != nil && !errors.As(err, &graphQLErr) {
+		return err
+	}
+
+	value := query.Elem()
+
+	if opts.RateLimit != nil {
+		opts.RateLimit.Add(value.Field(0).Interface().(RateLimit))
+	}
+
+	for i, org := range orgs {
+		organization := value.Field(i + 1)
+		if organization.IsNil() {
+			continue
+		}
+
+		firstPages.pages[strings.ToLower(org)] = organization.Elem().FieldByName("Repositories").Interface().(Repositories)
+	}
+
+	return err
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// GraphQLClient is the subset of api.GraphQLClient used to fetch repositories,
+// which allows the producers to run against a different implementation
+type GraphQLClient interface {
+	Query(name string, q any, variables map[string]any) error
+}
+
+// contextClient is implemented by the clients supporting cancellation, like api.GraphQLClient
+type contextClient interface {
+	QueryWithContext(ctx context.Context, name string, q any, variables map[string]any) error
+}
+
+// queryContext sends the query with ctx when the client supports it. Other clients only
+// see the cancellation between queries.
+func queryContext(ctx context.Context, client GraphQLClient, name string, q any, variables map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if withContext, ok := client.(contextClient); ok {
+		return withContext.QueryWithContext(ctx, name, q, variables)
+	}
+
+	return client.Query(name, q, variables)
+}
+
+// ClientOptions controls how NewClient builds the GraphQL client
+type ClientOptions struct {
+	// AuthToken overrides the token of the gh authentication when set
+	AuthToken string
+	// Host overrides the default gh host (GH_HOST or the authenticated host) when set
+	Host string
+	// DryRun prints every query and its variables to DryRunOutput instead of sending it
+	DryRun       bool
+	DryRunOutput io.Writer
+	// Headers are added to every request, e.g. an X-GitHub-Api-Version for a GHES release
+	Headers map[string]string
+}
+
+// ParseHeaders parses "key:value" entries into headers, rejecting the entries without a key
+// or with characters a header name can't contain. The value is trimmed and may be empty.
+func ParseHeaders(entries []string) (map[string]string, error) {
+	headers := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key, value, found := strings.Cut(entry, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid header %q, expected key:value", entry)
+		}
+
+		if strings.IndexFunc(key, func(r rune) bool { return r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r) }) >= 0 {
+			return nil, fmt.Errorf("invalid header name %q", key)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid value of header %q, it can't span lines", key)
+		}
+
+		headers[http.CanonicalHeaderKey(key)] = strings.TrimSpace(value)
+	}
+
+	return headers, nil
+}
+
+// NewClient returns the GraphQL client used by the producers
+func NewClient(opts ClientOptions) (GraphQLClient, error) {
+	if opts.DryRun {
+		host := opts.Host
+		if host == "" {
+			host, _ = auth.DefaultHost()
+		}
+
+		return api.NewGraphQLClient(api.ClientOptions{
+			Host: host,
+			// no request leaves the process so no real token is needed
+			AuthToken: "dry-run",
+			Headers:   opts.Headers,
+			Transport: dryRunTransport{w: opts.DryRunOutput},
+		})
+	}
+
+	// an empty token is resolved from the gh authentication
+	return api.NewGraphQLClient(api.ClientOptions{
+		AuthToken: opts.AuthToken,
+		Host:      opts.Host,
+		Headers:   opts.Headers,
+		Transport: statusTransport{base: http.DefaultTransport},
+	})
+}
+
+// statusTransport turns non-success responses into *api.HTTPError. The GraphQL
+// query client otherwise reports them as plain errors, losing the status code
+// needed to tell transient failures apart.
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, api.HandleHTTPError(resp)
+	}
+
+	return resp, nil
+}
+
+// TokenFromEnv returns the token of the GH_TOKEN or GITHUB_TOKEN environment variables
+func TokenFromEnv() string {
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token
+	}
+
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// dryRunTransport prints the GraphQL requests and answers them with empty data
+type dryRunTransport struct {
+	w io.Writer
+}
+
+func (t dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var payload struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+
+	if req.Body != nil {
+		defer req.Body.Close()
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, fmt.Errorf("decoding dry-run request: %w", err)
+		}
+	}
+
+	variables, err := json.Marshal(payload.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(t.w, "%s %s\nquery: %s\nvariables: %s\n\n", req.Method, req.URL, payload.Query, variables)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"data":{}}`)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// collaboratorConcurrency bounds the number of collaborator queries in flight across all sources
+const collaboratorConcurrency = 4
+
+type GetRepositoryCollaboratorQuery struct {
+	RateLimit  RateLimit
+	Repository struct {
+		Collaborators struct {
+			TotalCount int
+		} `graphql:"collaborators(login: $login, first: 1)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// CollaboratorFilter keeps the repositories a user collaborates on. The repositories
+// connection has no such filter, so every repository costs an extra query.
+type CollaboratorFilter struct {
+	login string
+	// semaphore shared by all sources
+	slots chan struct{}
+}
+
+// NewCollaboratorFilter returns a filter keeping the repositories login collaborates on
+func NewCollaboratorFilter(login string) *CollaboratorFilter {
+	return &CollaboratorFilter{login: login, slots: make(chan struct{}, collaboratorConcurrency)}
+}
+
+// filter checks the repositories concurrently and returns the matching ones in their original order.
+// Repositories whose collaborators can't be listed (it requires push access) are skipped with a warning.
+func (f *CollaboratorFilter) filter(client GraphQLClient, rateLimit *RateLimitUsage, repos []Repository) []Repository {
+	matches := make([]bool, len(repos))
+
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		f.slots <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-f.slots }()
+
+			isCollaborator, err := f.check(client, rateLimit, repo)
+			if err != nil {
+				log.Printf("Warning: [%s]: checking collaborator %s: %v", repo.NameWithOwner, f.login, err)
+				fmt.Fprintf(os.Stderr, "Warning: cannot check the collaborators of %s: %v\n", repo.NameWithOwner, err)
+				return
+			}
+
+			matches[i] = isCollaborator
+		}()
+	}
+	wg.Wait()
+
+	var kept []Repository
+	for i, repo := range repos {
+		if matches[i] {
+			kept = append(kept, repo)
+		}
+	}
+
+	return kept
+}
+
+// check reports whether the user is a collaborator of the repository
+func (f *CollaboratorFilter) check(client GraphQLClient, rateLimit *RateLimitUsage, repo Repository) (bool, error) {
+	owner, name, _ := strings.Cut(repo.NameWithOwner, "/")
+
+	var query GetRepositoryCollaboratorQuery
+	variables := map[string]any{
+		"owner": graphql.String(owner),
+		"name":  graphql.String(name),
+		"login": graphql.String(f.login),
+	}
+
+	if err := client.Query("GetRepositoryCollaborator", &query, variables); err != nil {
+		return false, err
+	}
+
+	if rateLimit != nil {
+		rateLimit.Add(query.RateLimit)
+	}
+
+	return query.Repository.Collaborators.TotalCount > 0, nil
+}
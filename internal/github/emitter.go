@@ -0,0 +1,71 @@
+package github
+
+import "sync/atomic"
+
+// Emitter is what the producers send their results through. It counts the results of
+// every source with an atomic counter, so the count and the limit stay exact while the
+// sources are fetched concurrently.
+type Emitter struct {
+	results chan<- Result
+	// limit is the maximum number of results sent, 0 for no limit
+	limit int64
+	count atomic.Int64
+	// parent, when set, is the shared emitter whose limit applies too
+	parent *Emitter
+}
+
+// NewEmitter returns an Emitter sending to results, and at most limit results when limit > 0
+func NewEmitter(results chan<- Result, limit int) *Emitter {
+	return &Emitter{results: results, limit: int64(max(limit, 0))}
+}
+
+// WithLimit returns an Emitter for a single source sending at most limit results when limit > 0,
+// on top of the limit of e: whichever is reached first stops the source
+func (e *Emitter) WithLimit(limit int) *Emitter {
+	if limit <= 0 {
+		return e
+	}
+
+	return &Emitter{results: e.results, limit: int64(limit), parent: e}
+}
+
+// Emit sends the result unless the limit was reached and reports whether it was sent
+func (e *Emitter) Emit(result Result) bool {
+	if !e.reserve() {
+		return false
+	}
+
+	e.results <- result
+	return true
+}
+
+// reserve takes a slot of e and of its parent, so concurrent producers never exceed a limit
+func (e *Emitter) reserve() bool {
+	for {
+		count := e.count.Load()
+		if e.limit > 0 && count >= e.limit {
+			return false
+		}
+
+		if e.count.CompareAndSwap(count, count+1) {
+			break
+		}
+	}
+
+	if e.parent != nil && !e.parent.reserve() {
+		e.count.Add(-1)
+		return false
+	}
+
+	return true
+}
+
+// Full reports whether the limit was reached, producers stop paginating once it is
+func (e *Emitter) Full() bool {
+	return (e.limit > 0 && e.count.Load() >= e.limit) || (e.parent != nil && e.parent.Full())
+}
+
+// Count returns the number of results sent so far
+func (e *Emitter) Count() int {
+	return int(e.count.Load())
+}
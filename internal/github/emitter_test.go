@@ -0,0 +1,69 @@
+package github
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// emitConcurrently runs producers goroutines emitting perProducer results each through emitter
+// until it refuses them, and returns the number of results received by the consumer
+func emitConcurrently(results chan Result, emitter *Emitter, producers, perProducer int) int {
+	var wg sync.WaitGroup
+	for producer := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			source := Source{Kind: SourceOrg, Login: fmt.Sprintf("org-%d", producer)}
+			for i := range perProducer {
+				if !emitter.Emit(Result{Source: source, Repository: Repository{NameWithOwner: fmt.Sprintf("%s/repo-%d", source.Login, i)}}) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	received := 0
+	for range results {
+		received++
+	}
+
+	return received
+}
+
+func TestEmitterCountsConcurrentProducers(t *testing.T) {
+	results := make(chan Result)
+	emitter := NewEmitter(results, 0)
+
+	received := emitConcurrently(results, emitter, 50, 200)
+	if received != 50*200 {
+		t.Errorf("received %d results, want %d", received, 50*200)
+	}
+
+	if emitter.Count() != received {
+		t.Errorf("counted %d results, received %d", emitter.Count(), received)
+	}
+}
+
+func TestEmitterLimitUnderConcurrency(t *testing.T) {
+	for _, limit := range []int{1, 7, 100} {
+		t.Run(fmt.Sprint(limit), func(t *testing.T) {
+			results := make(chan Result, 8)
+			emitter := NewEmitter(results, limit)
+
+			if received := emitConcurrently(results, emitter, 64, 50); received != limit {
+				t.Errorf("received %d results, want exactly the limit of %d", received, limit)
+			}
+
+			if !emitter.Full() || emitter.Count() != limit {
+				t.Errorf("Full() = %t and Count() = %d, want true and %d", emitter.Full(), emitter.Count(), limit)
+			}
+		})
+	}
+}
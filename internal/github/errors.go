@@ -0,0 +1,83 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// ErrSourceNotFound is returned by the producers when the user or organization
+// doesn't exist (or isn't visible with the current token)
+var ErrSourceNotFound = errors.New("not found")
+
+// ErrSourceForbidden is returned by the producers when the organization is suspended or
+// the token is blocked from it, the other sources are not affected
+var ErrSourceForbidden = errors.New("is suspended or blocked for your token")
+
+// ErrSourceTimeout is returned by the producers when a source takes longer than
+// Options.SourceTimeout, the repositories listed until then are kept
+var ErrSourceTimeout = errors.New("timed out")
+
+// ErrSourcePanic is returned for a source whose goroutine panicked (e.g. on an unexpected
+// nil in a response), the repositories listed until then are kept and the other sources finish
+var ErrSourcePanic = errors.New("failed with an internal error")
+
+// PanicError logs the recovered value of a panic of the goroutine of source with its stack trace
+// and wraps it into ErrSourcePanic
+func PanicError(source Source, value any) error {
+	log.Printf("[%s]: panic: %v\n%s", source, value, debug.Stack())
+	return fmt.Errorf("%w: %v (see the log for the stack trace)", ErrSourcePanic, value)
+}
+
+// ErrUnauthorized is returned by the producers when the API rejects the token (HTTP 401).
+// The token is shared by every source so none of them can succeed.
+var ErrUnauthorized = errors.New("authentication failed, the token is missing, invalid or expired")
+
+// isUnauthorized reports whether the API rejected the token
+func isUnauthorized(err error) bool {
+	var httpErr *api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
+
+// unauthorized converts an HTTP 401 error into ErrUnauthorized and returns any other error unchanged
+func unauthorized(err error) error {
+	if isUnauthorized(err) {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	return err
+}
+
+// sourceError converts the GraphQL errors about the source itself into ErrSourceNotFound
+// ("Could not resolve to a User/Organization") or ErrSourceForbidden (suspended organization,
+// token blocked by the organization, SAML or OAuth app restrictions) and returns any other
+// error unchanged
+func sourceError(source Source, err error) error {
+	var graphQLErr *api.GraphQLError
+	if !errors.As(err, &graphQLErr) {
+		return err
+	}
+
+	for _, item := range graphQLErr.Errors {
+		switch {
+		case item.Type == "NOT_FOUND":
+			return fmt.Errorf("%s %q %w", source.Kind, source.Login, ErrSourceNotFound)
+		case item.Type == "FORBIDDEN" || isSuspended(item.Message):
+			return fmt.Errorf("%s %q %w: %s", source.Kind, source.Login, ErrSourceForbidden, item.Message)
+		}
+	}
+
+	return err
+}
+
+// isSuspended reports whether a GraphQL error message is about a suspended or blocked account,
+// which isn't always reported with the FORBIDDEN type
+func isSuspended(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "suspended") || strings.Contains(message, "blocked")
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// badGateway is a transient failure, retried -retries times
+var badGateway = httpError(http.StatusBadGateway)
+
+// graphQLError returns the GraphQL error of a response with a single error item
+func graphQLError(errorType, message string) error {
+	return &api.GraphQLError{Errors: []api.GraphQLErrorItem{{Type: errorType, Message: message}}}
+}
+
+func TestSourceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+		// queries is the number of queries sent for the page, 1 when the error isn't retried
+		queries int
+	}{
+		{
+			name:    "organization not found",
+			err:     graphQLError("NOT_FOUND", "Could not resolve to an Organization with the login of 'acme'."),
+			want:    ErrSourceNotFound,
+			queries: 1,
+		},
+		{
+			name:    "suspended organization",
+			err:     graphQLError("FORBIDDEN", "Resource not accessible: the organization acme has been suspended."),
+			want:    ErrSourceForbidden,
+			queries: 1,
+		},
+		{
+			name:    "SAML enforcement",
+			err:     graphQLError("FORBIDDEN", "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization."),
+			want:    ErrSourceForbidden,
+			queries: 1,
+		},
+		{
+			name:    "blocked without an error type",
+			err:     graphQLError("", "Although you appear to have the correct authorization credentials, the `acme` organization has blocked your access."),
+			want:    ErrSourceForbidden,
+			queries: 1,
+		},
+		{
+			name:    "rejected token",
+			err:     httpError(http.StatusUnauthorized),
+			want:    ErrUnauthorized,
+			queries: 1,
+		},
+		{
+			name:    "bad gateway is retried",
+			err:     badGateway,
+			want:    badGateway,
+			queries: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newFakeClient()
+			client.addOwner("acme", 10)
+			client.addOwner("other", 10)
+			client.fail = func(login string, page, attempt int) error {
+				if login == "acme" {
+					return test.err
+				}
+				return nil
+			}
+
+			opts := Options{Client: client, Retries: 3, RetryDelay: time.Millisecond}
+			_, err := collect(t, 0, func(emitter *Emitter) error {
+				return ProcessOrgRepositories("acme", opts, emitter)
+			})
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+
+			if queries := client.queryCount("acme"); queries != test.queries {
+				t.Errorf("sent %d queries, want %d", queries, test.queries)
+			}
+
+			// the error only concerns its own source
+			results, err := collect(t, 0, func(emitter *Emitter) error {
+				return ProcessOrgRepositories("other", opts, emitter)
+			})
+			if err != nil || len(results) != 10 {
+				t.Errorf("other source: %d repositories and error %v, want 10 and no error", len(results), err)
+			}
+		})
+	}
+}
+
+func TestUnknownOwnerIsNotFound(t *testing.T) {
+	client := newFakeClient()
+
+	_, err := collect(t, 0, func(emitter *Emitter) error {
+		return ProcessOwnerRepositories("nobody", Options{Client: client}, emitter)
+	})
+	if !errors.Is(err, ErrSourceNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrSourceNotFound)
+	}
+}
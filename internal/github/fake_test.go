@@ -0,0 +1,203 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// TestMain keeps the logs of the producers out of the test output
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// fakeClient answers the repositories queries from in-memory owners. The cursor of a
+// page is the index of its first repository, like the offsets of a real connection.
+type fakeClient struct {
+	mu sync.Mutex
+	// owners maps a login to its repositories
+	owners map[string][]Repository
+	// users are the logins resolving to a user, the other owners are organizations
+	users map[string]bool
+	// totalCount overrides the TotalCount reported for a login
+	totalCount map[string]int
+	// endless reports HasNextPage on every page, like a pagination that never ends
+	endless bool
+	// latency is the time taken to answer every page
+	latency time.Duration
+	// fail, when set, returns the error of a query for login at page (from 1), attempt
+	// counting the queries of that page (from 1)
+	fail func(login string, page, attempt int) error
+	// queries counts the repositories queries per login
+	queries  map[string]int
+	attempts map[string]int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		owners:     map[string][]Repository{},
+		users:      map[string]bool{},
+		totalCount: map[string]int{},
+		queries:    map[string]int{},
+		attempts:   map[string]int{},
+	}
+}
+
+// addOwner adds n repositories named "<login>/repo-000" onwards to login
+func (c *fakeClient) addOwner(login string, n int) {
+	for i := range n {
+		c.owners[login] = append(c.owners[login], Repository{
+			NameWithOwner: fmt.Sprintf("%s/repo-%03d", login, i),
+			PushedAt:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(i) * time.Hour),
+		})
+	}
+}
+
+func (c *fakeClient) Query(name string, q any, variables map[string]any) error {
+	switch query := q.(type) {
+	case *GetOrgRepositoriesQuery:
+		repositories, err := c.page(string(variables["org"].(graphql.String)), variables)
+		query.Organization.Repositories = repositories
+		return err
+	case *GetUserRepositoriesQuery:
+		repositories, err := c.page(string(variables["username"].(graphql.String)), variables)
+		query.User.Repositories = repositories
+		return err
+	case *GetRepositoryOwnerQuery:
+		login := string(variables["login"].(graphql.String))
+		if _, found := c.owners[login]; !found {
+			return nil
+		}
+
+		query.RepositoryOwner = &struct {
+			Typename string `graphql:"__typename"`
+		}{Typename: "Organization"}
+		if c.users[login] {
+			query.RepositoryOwner.Typename = "User"
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected query %s (%T)", name, q)
+	}
+}
+
+// page returns the page of the repositories of login selected by the first and cursor variables
+func (c *fakeClient) page(login string, variables map[string]any) (Repositories, error) {
+	start := 0
+	if cursor, ok := variables["cursor"].(graphql.String); ok {
+		start, _ = strconv.Atoi(string(cursor))
+	}
+	first := int(variables["first"].(graphql.Int))
+	page := start/first + 1
+
+	c.mu.Lock()
+	c.queries[login]++
+	key := fmt.Sprintf("%s %d", login, page)
+	c.attempts[key]++
+	attempt := c.attempts[key]
+	c.mu.Unlock()
+
+	time.Sleep(c.latency)
+
+	if c.fail != nil {
+		if err := c.fail(login, page, attempt); err != nil {
+			return Repositories{}, err
+		}
+	}
+
+	repos, found := c.owners[login]
+	if !found {
+		return Repositories{}, &api.GraphQLError{Errors: []api.GraphQLErrorItem{{
+			Type:    "NOT_FOUND",
+			Message: fmt.Sprintf("Could not resolve to an Organization with the login of '%s'.", login),
+		}}}
+	}
+
+	end := min(start+first, len(repos))
+	var repositories Repositories
+	if start < end {
+		repositories.Nodes = repos[start:end]
+	}
+
+	repositories.TotalCount = len(repos)
+	if total, found := c.totalCount[login]; found {
+		repositories.TotalCount = total
+	}
+
+	repositories.PageInfo.EndCursor = strconv.Itoa(start + first)
+	repositories.PageInfo.HasNextPage = c.endless || end < len(repos)
+
+	return repositories, nil
+}
+
+// queryCount returns the number of repositories queries sent for login
+func (c *fakeClient) queryCount(login string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.queries[login]
+}
+
+// httpError returns the *api.HTTPError of a response with the given status
+func httpError(status int) error {
+	requestURL, _ := url.Parse("https://api.github.com/graphql")
+	return &api.HTTPError{StatusCode: status, Message: strconv.Itoa(status), RequestURL: requestURL}
+}
+
+// collect runs the producer with an emitter limited to limit and returns what it emitted
+func collect(t *testing.T, limit int, produce func(emitter *Emitter) error) ([]Result, error) {
+	t.Helper()
+
+	results := make(chan Result)
+	emitter := NewEmitter(results, limit)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- produce(emitter)
+		close(results)
+	}()
+
+	var collected []Result
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return collected, <-done
+			}
+			collected = append(collected, result)
+		case <-timeout:
+			t.Fatal("the producer didn't finish")
+		}
+	}
+}
+
+// names returns the NameWithOwner of the results
+func names(results []Result) []string {
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Repository.NameWithOwner)
+	}
+
+	return names
+}
+
+// repoNames returns the NameWithOwner of the repositories
+func repoNames(repos []Repository) []string {
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.NameWithOwner)
+	}
+
+	return names
+}
@@ -0,0 +1,249 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Filter reports whether a repository should be emitted by the producers.
+// It is used for the filters the GraphQL repositories connection doesn't support.
+type Filter func(repo Repository) bool
+
+// keep reports whether the repository passes every filter of the options
+func (opts Options) keep(repo Repository) bool {
+	for _, filter := range opts.Filters {
+		if !filter(repo) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NoTemplates excludes template repositories
+func NoTemplates(repo Repository) bool {
+	return !repo.IsTemplate
+}
+
+// OnlyTemplates keeps template repositories only
+func OnlyTemplates(repo Repository) bool {
+	return repo.IsTemplate
+}
+
+// NoDisabled excludes disabled repositories
+func NoDisabled(repo Repository) bool {
+	return !repo.IsDisabled
+}
+
+// NoEmpty excludes empty repositories (without any commit)
+func NoEmpty(repo Repository) bool {
+	return !repo.IsEmpty
+}
+
+// IssuesEnabled keeps the repositories whose issues are enabled, or disabled when enabled is false
+func IssuesEnabled(enabled bool) Filter {
+	return func(repo Repository) bool {
+		return repo.HasIssuesEnabled == enabled
+	}
+}
+
+// WikiEnabled keeps the repositories whose wiki is enabled, or disabled when enabled is false
+func WikiEnabled(enabled bool) Filter {
+	return func(repo Repository) bool {
+		return repo.HasWikiEnabled == enabled
+	}
+}
+
+// LargerThan keeps the repositories using more than the given kilobytes on disk.
+// Repositories with an unknown (0) disk usage never match.
+func LargerThan(kilobytes int) Filter {
+	return func(repo Repository) bool {
+		return repo.DiskUsage > 0 && repo.DiskUsage > kilobytes
+	}
+}
+
+// SmallerThan keeps the repositories using less than the given kilobytes on disk.
+// Repositories with an unknown (0) disk usage never match.
+func SmallerThan(kilobytes int) Filter {
+	return func(repo Repository) bool {
+		return repo.DiskUsage > 0 && repo.DiskUsage < kilobytes
+	}
+}
+
+// NoLicense is the Licenses value matching unlicensed repositories
+const NoLicense = "none"
+
+// Licenses keeps the repositories licensed under any of the given SPDX ids (case-insensitive),
+// NoLicense matches the repositories without a license
+func Licenses(spdxIDs []string) Filter {
+	return func(repo Repository) bool {
+		license := repo.License()
+		if license == "" {
+			license = NoLicense
+		}
+
+		for _, spdxID := range spdxIDs {
+			if strings.EqualFold(strings.TrimSpace(spdxID), license) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// NoTopics keeps the repositories without any topic
+func NoTopics(repo Repository) bool {
+	return repo.TopicCount() == 0
+}
+
+// MinTopics keeps the repositories with at least n topics
+func MinTopics(n int) Filter {
+	return func(repo Repository) bool {
+		return repo.TopicCount() >= n
+	}
+}
+
+// Match modes combining the name and description terms of Match
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// Match keeps the repositories whose "owner/name" contains nameTerm and whose description
+// contains descriptionTerm (case-insensitive), or either of them with MatchAny. Empty terms
+// are ignored and repositories without a description never match descriptionTerm.
+func Match(nameTerm, descriptionTerm, mode string) (Filter, error) {
+	if mode != MatchAll && mode != MatchAny {
+		return nil, fmt.Errorf("unknown match mode %q, expected %s or %s", mode, MatchAll, MatchAny)
+	}
+
+	nameTerm = strings.ToLower(nameTerm)
+	descriptionTerm = strings.ToLower(descriptionTerm)
+
+	return func(repo Repository) bool {
+		var matches []bool
+
+		if nameTerm != "" {
+			matches = append(matches, strings.Contains(strings.ToLower(repo.NameWithOwner), nameTerm))
+		}
+
+		if descriptionTerm != "" {
+			matches = append(matches, repo.Description != "" && strings.Contains(strings.ToLower(repo.Description), descriptionTerm))
+		}
+
+		if mode == MatchAny {
+			return slices.Contains(matches, true)
+		}
+
+		return !slices.Contains(matches, false)
+	}, nil
+}
+
+// ReleasedSince keeps the repositories whose latest release was created after since.
+// Repositories without a release are only kept with includeNoRelease.
+func ReleasedSince(since time.Time, includeNoRelease bool) Filter {
+	return func(repo Repository) bool {
+		release := repo.LatestRelease()
+		if release == nil {
+			return includeNoRelease
+		}
+
+		return release.CreatedAt.After(since)
+	}
+}
+
+// Fields searched by Query
+const (
+	QueryFieldName        = "name"
+	QueryFieldTopics      = "topics"
+	QueryFieldDescription = "description"
+)
+
+// QueryFields lists the fields Query can search
+var QueryFields = []string{QueryFieldName, QueryFieldTopics, QueryFieldDescription}
+
+// Query keeps the repositories where any of the given fields contains term (case-insensitive):
+// the "owner/name", any of the topics or the description
+func Query(term string, fields []string) (Filter, error) {
+	for _, field := range fields {
+		if !slices.Contains(QueryFields, field) {
+			return nil, fmt.Errorf("unknown query field %q, expected any of: %s", field, strings.Join(QueryFields, ", "))
+		}
+	}
+
+	term = strings.ToLower(term)
+
+	return func(repo Repository) bool {
+		for _, field := range fields {
+			var values []string
+			switch field {
+			case QueryFieldName:
+				values = []string{repo.NameWithOwner}
+			case QueryFieldTopics:
+				values = repo.Topics()
+			case QueryFieldDescription:
+				values = []string{repo.Description}
+			}
+
+			for _, value := range values {
+				if strings.Contains(strings.ToLower(value), term) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// DefaultBranch keeps the repositories whose default branch is the given one.
+// Repositories without a default branch (no commits yet) never match.
+func DefaultBranch(name string) Filter {
+	return func(repo Repository) bool {
+		return repo.DefaultBranch() == name
+	}
+}
+
+// Exclude drops the repositories whose "owner/name" matches any of the patterns.
+// Patterns use the path.Match syntax (e.g. "owner/*-archived") and are case-insensitive.
+func Exclude(patterns []string) (Filter, error) {
+	lowered := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		// validate upfront, path.Match only reports bad patterns while matching
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		lowered = append(lowered, pattern)
+	}
+
+	return func(repo Repository) bool {
+		name := strings.ToLower(repo.NameWithOwner)
+		for _, pattern := range lowered {
+			if matched, _ := path.Match(pattern, name); matched {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
+// Permissions lists the repository permissions of the viewer from the lowest to the highest
+var Permissions = []string{"READ", "TRIAGE", "WRITE", "MAINTAIN", "ADMIN"}
+
+// MinPermission keeps the repositories where the viewer has at least the given permission
+func MinPermission(permission string) (Filter, error) {
+	minimum := slices.Index(Permissions, strings.ToUpper(permission))
+	if minimum < 0 {
+		return nil, fmt.Errorf("unknown permission %q, expected one of: %s", permission, strings.Join(Permissions, ", "))
+	}
+
+	return func(repo Repository) bool {
+		return slices.Index(Permissions, repo.ViewerPermission) >= minimum
+	}, nil
+}
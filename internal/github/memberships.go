@@ -0,0 +1,58 @@
+package github
+
+import (
+	"fmt"
+	"log"
+
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+type GetUserOrganizationsQuery struct {
+	User struct {
+		Organizations struct {
+			Nodes []struct {
+				Login string
+			}
+			PageInfo struct {
+				EndCursor   string
+				HasNextPage bool
+			}
+		} `graphql:"organizations(first: $first, after: $cursor)"`
+	} `graphql:"user(login: $login)"`
+}
+
+// GetUserOrganizations returns the logins of the organizations of a user, following the
+// pagination. Only the public memberships are listed unless the token is the user's own
+// with the read:org scope.
+func GetUserOrganizations(client GraphQLClient, login string) ([]string, error) {
+	source := Source{Kind: SourceUser, Login: login}
+	variables := map[string]any{
+		"login":  graphql.String(login),
+		"first":  graphql.Int(MaxPageSize),
+		"cursor": (*graphql.String)(nil),
+	}
+
+	var orgs []string
+	for page := 1; ; page++ {
+		log.Printf("[%s]: getting organizations page %d...\n", login, page)
+
+		var query GetUserOrganizationsQuery
+		if err := client.Query("GetUserOrganizations", &query, variables); err != nil {
+			return nil, fmt.Errorf("getting the organizations: %w", unauthorized(sourceError(source, err)))
+		}
+
+		for _, org := range query.User.Organizations.Nodes {
+			orgs = append(orgs, org.Login)
+		}
+
+		if !query.User.Organizations.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = graphql.String(query.User.Organizations.PageInfo.EndCursor)
+	}
+
+	log.Printf("[%s]: member of %d organizations\n", login, len(orgs))
+
+	return orgs, nil
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// nameConcurrency bounds the number of single repository queries of ProcessRepositoryNames in flight
+const nameConcurrency = 8
+
+// ProcessRepositoryNames fetches the repositories of opts.Names with a query per repository, only
+// requesting the optional fields of opts.Fields, and sends the ones passing the filters to the
+// emitter in the order of the names. Repositories that no longer exist are skipped with a warning,
+// renamed ones are listed under their new name.
+func ProcessRepositoryNames(source Source, opts Options, emitter *Emitter) error {
+	log.Printf("[%s]: getting %d repositories...\n", source, len(opts.Names))
+
+	if opts.Progress != nil {
+		opts.Progress.SetTotal(source, len(opts.Names))
+		defer opts.Progress.Done(source)
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	repos := make([]*Repository, len(opts.Names))
+	errs := make([]error, len(opts.Names))
+	slots := make(chan struct{}, nameConcurrency)
+
+	var wg sync.WaitGroup
+	for i, name := range opts.Names {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			repo, err := getRepository(client, name, opts.Fields, opts.MaxTopics, false)
+			if opts.Progress != nil {
+				opts.Progress.Add(source, 1)
+			}
+
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			repos[i] = &repo
+		}()
+	}
+	wg.Wait()
+
+	var found []Repository
+	for i, name := range opts.Names {
+		if err := errs[i]; err != nil {
+			if !isRepositoryNotFound(err) {
+				return fmt.Errorf("getting %s: %w", name, unauthorized(err))
+			}
+
+			log.Printf("Warning: [%s]: %s no longer exists: %v\n", source, name, err)
+			fmt.Fprintf(os.Stderr, "Warning: %s no longer exists (or isn't visible with your token), skipped\n", name)
+			continue
+		}
+
+		if opts.DetectRename && Renamed(name, *repos[i]) {
+			fmt.Fprintf(os.Stderr, "Warning: %s -> %s, renamed or transferred\n", name, repos[i].NameWithOwner)
+		}
+
+		found = append(found, *repos[i])
+	}
+
+	opts.emit(client, source, found, emitter)
+
+	return nil
+}
+
+// isRepositoryNotFound reports whether the repository of a single repository query doesn't resolve
+func isRepositoryNotFound(err error) bool {
+	var graphQLErr *api.GraphQLError
+	return errors.As(err, &graphQLErr) && graphQLErr.Match("NOT_FOUND", "repository")
+}
@@ -1,31 +1,66 @@
 package github
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/arielschiavoni/gh-list-repos/internal/utils"
 	"github.com/cli/go-gh/v2/pkg/api"
 	graphql "github.com/cli/shurcooL-graphql"
 )
 
-const pageSize = 100
+// MaxPageSize is the largest page size allowed by the GitHub GraphQL API
+const MaxPageSize = 100
+
+// DefaultMaxTopics is the number of topics fetched per repository unless Options.MaxTopics is set
+const DefaultMaxTopics = 5
+
+// MaxTopics is the largest number of topics per repository allowed by the GitHub GraphQL API
+const MaxTopics = 100
 const maxLineWidth = 150
 
 type GetUserRepositoriesQuery struct {
-	User struct {
-		Repositories Repositories `graphql:"repositories(ownerAffiliations: OWNER, first: $first, after: $cursor, isArchived: $isArchived, isFork: $isFork)"`
+	RateLimit RateLimit
+	User      struct {
+		Repositories Repositories `graphql:"repositories(ownerAffiliations: OWNER, first: $first, after: $cursor, isArchived: $isArchived, isFork: $isFork, orderBy: $orderBy)"`
 	} `graphql:"user(login: $username)"`
 }
 
 type GetOrgRepositoriesQuery struct {
+	RateLimit    RateLimit
 	Organization struct {
-		Repositories Repositories `graphql:"repositories(first: $first, after: $cursor, isArchived: $isArchived, isFork: $isFork)"`
+		Repositories Repositories `graphql:"repositories(first: $first, after: $cursor, isArchived: $isArchived, isFork: $isFork, orderBy: $orderBy)"`
 	} `graphql:"organization(login: $org)"`
 }
 
+type GetRepositoryOwnerQuery struct {
+	RateLimit       RateLimit
+	RepositoryOwner *struct {
+		Typename string `graphql:"__typename"`
+	} `graphql:"repositoryOwner(login: $login)"`
+}
+
+type GetRepositoryQuery struct {
+	Repository Repository `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type RateLimit struct {
+	Limit     int
+	Cost      int
+	Remaining int
+	ResetAt   time.Time
+}
+
 type Repositories struct {
 	TotalCount int
 	Nodes      []Repository
@@ -37,27 +72,390 @@ type Repositories struct {
 
 type Repository struct {
 	NameWithOwner    string
+	URL              string
+	SSHURL           string `graphql:"sshUrl"`
 	IsFork           bool
 	IsArchived       bool
-	RepositoryTopics RepositoryTopics `graphql:"repositoryTopics(first: 5)"`
+	IsTemplate       bool
+	IsDisabled       bool
+	IsEmpty          bool
+	HasIssuesEnabled bool
+	HasWikiEnabled   bool
+	// DiskUsage is the size in kilobytes, 0 when GitHub didn't compute it (yet)
+	DiskUsage        int
+	DefaultBranchRef *Ref
+	PushedAt         time.Time
+	UpdatedAt        time.Time
+	ViewerPermission string
+	LicenseInfo      *License
+	Parent           *struct {
+		NameWithOwner string
+	}
+	Description      string           `graphql:"description @include(if: $withDescription)"`
+	PrimaryLanguage  *Language        `graphql:"primaryLanguage @include(if: $withLanguage)"`
+	RepositoryTopics RepositoryTopics `graphql:"repositoryTopics(first: $maxTopics) @include(if: $withTopics)"`
+	StargazerCount   int              `graphql:"stargazerCount @include(if: $withCounts)"`
+	ForkCount        int              `graphql:"forkCount @include(if: $withCounts)"`
+	Issues           struct {
+		TotalCount int
+	} `graphql:"issues(states: OPEN) @include(if: $withCounts)"`
+	PullRequests struct {
+		TotalCount int
+	} `graphql:"pullRequests(states: OPEN) @include(if: $withCounts)"`
+	// only the latest release is requested, keeping the cost at one extra node per repository
+	Releases struct {
+		Nodes []Release
+	} `graphql:"releases(first: 1, orderBy: {field: CREATED_AT, direction: DESC}) @include(if: $withRelease)"`
+	// OwnerProfile is only requested by GetRepository, see Owner for the login of the listings
+	OwnerProfile *RepositoryOwner `graphql:"owner @include(if: $withOwner)"`
+}
+
+// RepositoryOwner is the user or organization owning a repository
+type RepositoryOwner struct {
+	// Typename is either "User" or "Organization"
+	Typename  string `graphql:"__typename"`
+	Login     string
+	URL       string `graphql:"url"`
+	AvatarURL string `graphql:"avatarUrl"`
+}
+
+// Optional repository fields, only requested when they are part of Options.Fields
+const (
+	FieldTopics      = "topics"
+	FieldDescription = "description"
+	// FieldLastCommit is the date and author of the last commit on the default branch
+	FieldLastCommit = "last-commit"
+	FieldLanguage   = "language"
+	// FieldCounts is the number of stars and forks and of open issues and pull requests
+	FieldCounts = "counts"
+	// FieldRelease is the tag and creation date of the latest release
+	FieldRelease = "release"
+)
+
+// OptionalFields lists every field that can be passed in Options.Fields
+var OptionalFields = []string{FieldTopics, FieldDescription, FieldLastCommit, FieldLanguage, FieldCounts, FieldRelease}
+
+// fieldVariables returns the variables driving the @include directives of the optional fields,
+// and the number of topics fetched (DefaultMaxTopics when maxTopics is 0)
+func fieldVariables(fields []string, maxTopics int) map[string]any {
+	if maxTopics <= 0 || maxTopics > MaxTopics {
+		maxTopics = DefaultMaxTopics
+	}
+
+	variables := map[string]any{
+		"maxTopics":       graphql.Int(maxTopics),
+		"withTopics":      graphql.Boolean(false),
+		"withDescription": graphql.Boolean(false),
+		"withLastCommit":  graphql.Boolean(false),
+		"withLanguage":    graphql.Boolean(false),
+		"withCounts":      graphql.Boolean(false),
+		"withRelease":     graphql.Boolean(false),
+		// not an optional field of the listings, only GetRepository requests the owner
+		"withOwner": graphql.Boolean(false),
+	}
+
+	for _, field := range fields {
+		switch field {
+		case FieldTopics:
+			variables["withTopics"] = graphql.Boolean(true)
+		case FieldDescription:
+			variables["withDescription"] = graphql.Boolean(true)
+		case FieldLastCommit:
+			variables["withLastCommit"] = graphql.Boolean(true)
+		case FieldLanguage:
+			variables["withLanguage"] = graphql.Boolean(true)
+		case FieldCounts:
+			variables["withCounts"] = graphql.Boolean(true)
+		case FieldRelease:
+			variables["withRelease"] = graphql.Boolean(true)
+		}
+	}
+
+	return variables
+}
+
+type License struct {
+	SpdxID string `graphql:"spdxId"`
+}
+
+type Language struct {
+	Name string
+}
+
+type Ref struct {
+	Name   string
+	Target struct {
+		Commit Commit `graphql:"... on Commit"`
+	} `graphql:"target @include(if: $withLastCommit)"`
+}
+
+type Release struct {
+	CreatedAt time.Time
+	TagName   string
+}
+
+type Commit struct {
+	CommittedDate time.Time
+	Author        struct {
+		Name string
+	}
 }
 
 type RepositoryTopics struct {
-	Nodes []struct {
+	// TotalCount counts every topic, Nodes only holds the first ones
+	TotalCount int
+	Nodes      []struct {
 		Topic struct {
 			Name string
 		}
 	}
 }
 
+// Options controls which repositories are fetched by the producers
+type Options struct {
+	NoArchived bool
+	NoFork     bool
+	// PageSize is the number of repositories requested per page, MaxPageSize when 0
+	PageSize int
+	// Retries is the number of times a page is re-fetched after a transient failure
+	Retries int
+	// RetryDelay is the wait before the first retry, doubled on every attempt (1s when 0)
+	RetryDelay time.Duration
+	// SourceTimeout bounds the time spent paginating each source when > 0, a source timing out
+	// returns ErrSourceTimeout after emitting what it fetched, the others continue
+	SourceTimeout time.Duration
+	// RetryLog, when set, records every retry attempt
+	RetryLog *RetryLog
+	// NormalizeTopics lowercases, trims and de-duplicates the topics of every repository
+	NormalizeTopics bool
+	// Filters are applied client-side to every fetched repository
+	Filters []Filter
+	// Fields lists the optional fields (see OptionalFields) to request
+	Fields []string
+	// Client is used to send the queries, api.DefaultGraphQLClient is used when nil
+	Client GraphQLClient
+	// Progress is notified about the fetched repositories of each source when set
+	Progress Progress
+	// RateLimit accumulates the rate limit usage of every page when set
+	RateLimit *RateLimitUsage
+	// SinceCache, when set, orders the repositories by pushedAt and serves the ones
+	// not pushed since the previous run from the cache instead of fetching them
+	SinceCache *SinceCache
+	// Resume, when set, records the cursor of every fetched page and resumes unfinished sources from it
+	Resume *ResumeState
+	// Collaborator, when set, only keeps the repositories the given user collaborates on
+	Collaborator *CollaboratorFilter
+	// Limit is the maximum number of repositories emitted across all sources, 0 for no limit
+	Limit int
+	// MaxTopics is the number of topics fetched per repository, DefaultMaxTopics when 0
+	MaxTopics int
+	// LimitPerSource is the maximum number of repositories emitted by each source, 0 for no limit
+	LimitPerSource int
+	// Names are the "owner/name" of the repositories of the SourceNames source
+	Names []string
+	// DetectRename warns about the Names that were renamed or transferred since
+	DetectRename bool
+	// FirstPageOnly stops every source after its first page (up to PageSize repositories)
+	FirstPageOnly bool
+	// MaxConcurrency bounds the number of sources fetched at the same time, 0 for no bound
+	MaxConcurrency int
+	// MaxPages caps the number of pages fetched per source, guarding against a pagination
+	// that never ends (HasNextPage always true), 0 for no cap
+	MaxPages int
+	// BatchOrgs fetches the first page of the organizations with aliased queries (see FetchOrgFirstPages)
+	BatchOrgs bool
+	// FirstPages, when set, holds the first page of the organizations fetched by FetchOrgFirstPages
+	FirstPages *FirstPages
+}
+
+// LineOptions controls which optional details are rendered by Line
+type LineOptions struct {
+	ShowURL bool
+	// URLType is either "https" (default) or "ssh"
+	URLType string
+	// ShowBranch adds the default branch name
+	ShowBranch bool
+	// ShowSize adds the disk usage (e.g. "1.5 MB")
+	ShowSize bool
+	// ShowLicense adds the SPDX id of the license
+	ShowLicense bool
+	// ShowPermission adds the permission of the viewer (e.g. "admin")
+	ShowPermission bool
+	// ShowCounts adds the number of stars, forks, open issues and open pull requests
+	ShowCounts bool
+	// ShowLanguage adds the primary language
+	ShowLanguage bool
+	// ShowRelease adds the tag and date of the latest release
+	ShowRelease bool
+	// ShowUpdated adds how long ago the repository was updated (e.g. "2d ago")
+	ShowUpdated bool
+	// Now is the reference time of ShowUpdated, time.Now() when zero
+	Now time.Time
+	// SortTopicsByFrequency orders the topics by TopicFrequency instead of alphabetically
+	SortTopicsByFrequency bool
+	// TopicFrequency is the number of repositories with each topic, computed once every repository is known
+	TopicFrequency map[string]int
+	// Compact leaves the topics out of the line, the markers and the other details are kept
+	Compact bool
+	// ColorTopics renders every topic in a color derived from its name, see utils.ColorByHash
+	ColorTopics bool
+	// NoPadding separates the name from the details with a tab instead of padding the line to maxLineWidth
+	NoPadding bool
+	// OwnerWidth renders the owner as a column of the given width followed by the repository name when > 0
+	OwnerWidth int
+	// NameWidth and LanguageWidth render the language as a column after the name when LanguageWidth > 0
+	NameWidth     int
+	LanguageWidth int
+}
+
+// CloneURL returns the HTTPS or SSH clone URL of the repository depending on urlType
+func (r Repository) CloneURL(urlType string) string {
+	if urlType == "ssh" {
+		return r.SSHURL
+	}
+
+	return r.URL
+}
+
+// DefaultBranch returns the name of the default branch, empty for repositories without commits
+func (r Repository) DefaultBranch() string {
+	if r.DefaultBranchRef == nil {
+		return ""
+	}
+
+	return r.DefaultBranchRef.Name
+}
+
+// Language returns the name of the primary language, empty when unknown or not fetched
+func (r Repository) Language() string {
+	if r.PrimaryLanguage == nil {
+		return ""
+	}
+
+	return r.PrimaryLanguage.Name
+}
+
+// License returns the SPDX id of the license (e.g. "MIT"), empty for unlicensed repositories
+func (r Repository) License() string {
+	if r.LicenseInfo == nil {
+		return ""
+	}
+
+	return r.LicenseInfo.SpdxID
+}
+
+// LastCommit returns the last commit on the default branch, nil for empty repositories
+// or when the last-commit field was not fetched
+func (r Repository) LastCommit() *Commit {
+	if r.DefaultBranchRef == nil || r.DefaultBranchRef.Target.Commit.CommittedDate.IsZero() {
+		return nil
+	}
+
+	return &r.DefaultBranchRef.Target.Commit
+}
+
+// LatestRelease returns the most recently created release, nil when the repository
+// has no release or the release field was not fetched
+func (r Repository) LatestRelease() *Release {
+	if len(r.Releases.Nodes) == 0 {
+		return nil
+	}
+
+	return &r.Releases.Nodes[0]
+}
+
+// Owner returns the login of the repository owner
+func (r Repository) Owner() string {
+	owner, _, _ := strings.Cut(r.NameWithOwner, "/")
+	return owner
+}
+
+// Name returns the repository name without its owner
+func (r Repository) Name() string {
+	_, name, _ := strings.Cut(r.NameWithOwner, "/")
+	return name
+}
+
+// Topics returns the topic names of the repository sorted alphabetically
+func (r Repository) Topics() []string {
+	topics := make([]string, 0, len(r.RepositoryTopics.Nodes))
+	for _, node := range r.RepositoryTopics.Nodes {
+		topics = append(topics, node.Topic.Name)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// TopicsByFrequency returns the topic names of the repository, the most frequent ones
+// first according to frequency and alphabetically on ties
+func (r Repository) TopicsByFrequency(frequency map[string]int) []string {
+	topics := r.Topics()
+	slices.SortStableFunc(topics, func(a, b string) int {
+		return cmp.Compare(frequency[b], frequency[a])
+	})
+
+	return topics
+}
+
+// NameColumn returns the name part of Line: "owner/name", or the owner padded to
+// opts.OwnerWidth followed by the name
+func (r Repository) NameColumn(opts LineOptions) string {
+	if opts.OwnerWidth == 0 {
+		return r.NameWithOwner
+	}
+
+	return utils.AlignColumns([]string{r.Owner(), r.Name()}, []int{opts.OwnerWidth})
+}
+
+// ParentName returns the "owner/name" of the repository a fork was created from, empty for other repositories
+func (r Repository) ParentName() string {
+	if r.Parent == nil {
+		return ""
+	}
+
+	return r.Parent.NameWithOwner
+}
+
+// TopicCount returns the number of topics, including the ones beyond the fetched first few
+func (r Repository) TopicCount() int {
+	return r.RepositoryTopics.TotalCount
+}
+
+// normalizeTopics lowercases and trims the topic names and drops duplicates, keeping the first occurrence.
+// The nodes are copied so the slice shared with the fetched page (and the since cache) is left untouched.
+func (r Repository) normalizeTopics() Repository {
+	nodes := r.RepositoryTopics.Nodes
+	r.RepositoryTopics.Nodes = nil
+
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		name := strings.ToLower(strings.TrimSpace(node.Topic.Name))
+		if name == "" || seen[name] {
+			r.RepositoryTopics.TotalCount--
+			continue
+		}
+		seen[name] = true
+
+		node.Topic.Name = name
+		r.RepositoryTopics.Nodes = append(r.RepositoryTopics.Nodes, node)
+	}
+
+	return r
+}
+
 // Creates a unique repo description line based on the name and other repository details like topics
-func (r Repository) Line() string {
+func (r Repository) Line(opts LineOptions) string {
 	// the key is composed of a "left" side (NameWithOwner) and right side (IsArchived, IsFork, and topics)
-	left := r.NameWithOwner
+	left := r.NameColumn(opts)
+
+	// the language is its own column when its width is known, otherwise one more detail on the right side
+	if opts.ShowLanguage && opts.LanguageWidth > 0 {
+		left = utils.AlignColumns([]string{left, r.Language()}, []int{opts.NameWidth, opts.LanguageWidth})
+	}
 
 	var right []string
 
-	// Add warning color if the repository is archived
 	if r.IsArchived {
 		right = append(right, "archived")
 	}
@@ -66,15 +464,66 @@ func (r Repository) Line() string {
 		right = append(right, "fork")
 	}
 
-	if len(r.RepositoryTopics.Nodes) > 0 {
-		topics := make([]string, 0, len(r.RepositoryTopics.Nodes))
-		for _, node := range r.RepositoryTopics.Nodes {
-			topics = append(topics, node.Topic.Name)
+	if r.IsTemplate {
+		right = append(right, "template")
+	}
+
+	if r.IsDisabled {
+		right = append(right, "disabled")
+	}
+
+	if opts.ShowSize && r.DiskUsage > 0 {
+		right = append(right, utils.FormatSize(r.DiskUsage))
+	}
+
+	if opts.ShowLicense && r.License() != "" {
+		right = append(right, r.License())
+	}
+
+	if opts.ShowPermission && r.ViewerPermission != "" {
+		right = append(right, strings.ToLower(r.ViewerPermission))
+	}
+
+	if opts.ShowLanguage && opts.LanguageWidth == 0 && r.Language() != "" {
+		right = append(right, r.Language())
+	}
+
+	if opts.ShowBranch && r.DefaultBranch() != "" {
+		right = append(right, r.DefaultBranch())
+	}
+
+	if release := r.LatestRelease(); opts.ShowRelease && release != nil {
+		right = append(right, fmt.Sprintf("%s (%s)", release.TagName, release.CreatedAt.Local().Format("2006-01-02")))
+	}
+
+	if opts.ShowUpdated && !r.UpdatedAt.IsZero() {
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		right = append(right, utils.FormatRelative(r.UpdatedAt, now))
+	}
+
+	if len(r.RepositoryTopics.Nodes) > 0 && !opts.Compact {
+		topics := r.Topics()
+		if opts.SortTopicsByFrequency {
+			topics = r.TopicsByFrequency(opts.TopicFrequency)
+		}
+
+		if opts.ColorTopics {
+			for i, topic := range topics {
+				topics[i] = utils.ColorByHash(topic)
+			}
 		}
-		// Sort the topics alphabetically
-		sort.Strings(topics)
 		right = append(right, fmt.Sprintf("[%s]", strings.Join(topics, ",")))
+	}
+
+	if opts.ShowCounts {
+		right = append(right, fmt.Sprintf("★%d ⑂%d ◎%d ⇄%d", r.StargazerCount, r.ForkCount, r.Issues.TotalCount, r.PullRequests.TotalCount))
+	}
 
+	if opts.ShowURL {
+		right = append(right, r.CloneURL(opts.URLType))
 	}
 
 	// if the right part is empty then return only the left side
@@ -82,118 +531,508 @@ func (r Repository) Line() string {
 		return left
 	}
 
-	// if the right part contains either "archived", "fork" or a list of topics
-	// then it needs to be aligned to right side and the available space determined by maxLineWidth
-	// needs to be filled with spaces
-	return utils.AlignStrings(left, strings.Join(right, " | "), maxLineWidth)
+	return opts.join(left, strings.Join(right, " | "))
 }
 
-func ProcessUserRepositories(username string, noArchived bool, noFork bool, repoLinesChannel chan string) error {
-	log.Printf("[%s]: getting repositories...\n", username)
-	client, err := api.DefaultGraphQLClient()
-	if err != nil {
-		log.Fatal(err)
+// join renders the two sides of a line: the right side is aligned to the right of the
+// maxLineWidth columns, filling the space in between, or just separated with a tab with NoPadding
+func (opts LineOptions) join(left, right string) string {
+	if opts.NoPadding {
+		return left + "\t" + right
+	}
+
+	return utils.AlignStrings(left, right, maxLineWidth)
+}
+
+// repositoriesQuery is implemented by the user and organization queries
+// so both sources share the same pagination loop
+type repositoriesQuery interface {
+	repositories() Repositories
+	rateLimit() RateLimit
+}
+
+// repositoriesVariables returns the variables shared by every repositories connection query
+func (opts Options) repositoriesVariables() map[string]any {
+	pageSize := opts.PageSize
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		pageSize = MaxPageSize
 	}
 
-	var query GetUserRepositoriesQuery
 	variables := map[string]any{
-		"username":   graphql.String(username),
 		"first":      graphql.Int(pageSize),
 		"cursor":     (*graphql.String)(nil),
 		"isArchived": (*graphql.Boolean)(nil),
 		"isFork":     (*graphql.Boolean)(nil),
+		"orderBy":    (*RepositoryOrder)(nil),
 	}
 
-	if noArchived {
+	for name, value := range fieldVariables(opts.Fields, opts.MaxTopics) {
+		variables[name] = value
+	}
+
+	if opts.NoArchived {
 		variables["isArchived"] = graphql.Boolean(false)
 	}
 
-	if noFork {
+	if opts.NoFork {
 		variables["isFork"] = graphql.Boolean(false)
 	}
 
-	page := 1
+	// the since cache stops at the first repository unchanged since the previous run
+	if opts.SinceCache != nil {
+		variables["orderBy"] = pushedAtOrder
+	}
 
-	for {
-		log.Printf("[%s]: getting page %d...\n", username, page)
+	return variables
+}
 
-		err = client.Query("GetUserRepositories", &query, variables)
-		if err != nil {
-			log.Fatal(err)
-		}
+func (q *GetUserRepositoriesQuery) repositories() Repositories { return q.User.Repositories }
+func (q *GetUserRepositoriesQuery) rateLimit() RateLimit       { return q.RateLimit }
+func (q *GetOrgRepositoriesQuery) repositories() Repositories  { return q.Organization.Repositories }
+func (q *GetOrgRepositoriesQuery) rateLimit() RateLimit        { return q.RateLimit }
 
-		if page == 1 {
-			log.Printf("[%s]: has %d repos\n", username, query.User.Repositories.TotalCount)
-		}
+func ProcessUserRepositories(username string, opts Options, emitter *Emitter) error {
+	source := Source{Kind: SourceUser, Login: username}
+	return processUserRepositories(source, opts, emitter)
+}
 
-		for _, repo := range query.User.Repositories.Nodes {
-			// send repo line to channel
-			repoLinesChannel <- repo.Line()
-		}
+func ProcessOrgRepositories(org string, opts Options, emitter *Emitter) error {
+	source := Source{Kind: SourceOrg, Login: org}
+	return processOrgRepositories(source, opts, emitter)
+}
 
-		if !query.User.Repositories.PageInfo.HasNextPage {
-			break
+// ProcessOwnerRepositories resolves whether login is a user or an organization
+// and fetches its repositories with the matching query
+func ProcessOwnerRepositories(login string, opts Options, emitter *Emitter) error {
+	source := Source{Kind: SourceOwner, Login: login}
+
+	kind, err := resolveOwner(login, opts)
+	if err != nil {
+		if opts.Progress != nil {
+			opts.Progress.Done(source)
 		}
 
-		variables["cursor"] = graphql.String(query.User.Repositories.PageInfo.EndCursor)
-		page += 1
+		return fmt.Errorf("resolving owner: %w", unauthorized(sourceError(source, err)))
+	}
 
+	log.Printf("[%s]: resolved to %s\n", login, kind)
+
+	// results keep the unresolved source, so they are attributed to what was asked for
+	if kind == SourceUser {
+		return processUserRepositories(source, opts, emitter)
 	}
 
-	return nil
+	return processOrgRepositories(source, opts, emitter)
+}
+
+func processUserRepositories(source Source, opts Options, emitter *Emitter) error {
+	variables := map[string]any{
+		"username": graphql.String(source.Login),
+	}
+
+	newQuery := func() repositoriesQuery { return &GetUserRepositoriesQuery{} }
+
+	return processRepositories(source, "GetUserRepositories", newQuery, variables, opts, emitter)
 }
 
-func ProcessOrgRepositories(org string, noArchived bool, noFork bool, repoLinesChannel chan string) error {
-	log.Printf("[%s]: getting repositories...\n", org)
-	client, err := api.DefaultGraphQLClient()
+func processOrgRepositories(source Source, opts Options, emitter *Emitter) error {
+	variables := map[string]any{
+		"org": graphql.String(source.Login),
+	}
+
+	newQuery := func() repositoriesQuery { return &GetOrgRepositoriesQuery{} }
+
+	return processRepositories(source, "GetOrgRepositories", newQuery, variables, opts, emitter)
+}
+
+// resolveOwner queries whether login is a user or an organization
+func resolveOwner(login string, opts Options) (SourceKind, error) {
+	client, err := opts.client()
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
-	var query GetOrgRepositoriesQuery
+	var query GetRepositoryOwnerQuery
 	variables := map[string]any{
-		"org":        graphql.String(org),
-		"first":      graphql.Int(pageSize),
-		"cursor":     (*graphql.String)(nil),
-		"isArchived": (*graphql.Boolean)(nil),
-		"isFork":     (*graphql.Boolean)(nil),
+		"login": graphql.String(login),
 	}
 
-	if noArchived {
-		variables["isArchived"] = graphql.Boolean(false)
+	log.Printf("[%s]: resolving owner type...\n", login)
+
+	if err := client.Query("GetRepositoryOwner", &query, variables); err != nil {
+		return "", err
 	}
 
-	if noFork {
-		variables["isFork"] = graphql.Boolean(false)
+	if opts.RateLimit != nil {
+		opts.RateLimit.Add(query.RateLimit)
 	}
 
+	if query.RepositoryOwner == nil {
+		// unknown logins resolve to null rather than a NOT_FOUND error
+		return "", fmt.Errorf("%s %q %w", SourceOwner, login, ErrSourceNotFound)
+	}
+
+	if query.RepositoryOwner.Typename == "User" {
+		return SourceUser, nil
+	}
+
+	return SourceOrg, nil
+}
+
+// client returns the client of the options, falling back to the gh authentication
+func (opts Options) client() (GraphQLClient, error) {
+	if opts.Client != nil {
+		return opts.Client, nil
+	}
+
+	return api.DefaultGraphQLClient()
+}
+
+// processRepositories paginates through the repositories connection of a source
+// and sends every repository through emitter
+func processRepositories(source Source, queryName string, newQuery func() repositoriesQuery, variables map[string]any, opts Options, emitter *Emitter) error {
+	login := source.Login
+	log.Printf("[%s]: getting repositories...\n", login)
+
+	if opts.Progress != nil {
+		defer opts.Progress.Done(source)
+	}
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	for name, value := range opts.repositoriesVariables() {
+		variables[name] = value
+	}
+
+	// repositories of the previous run and the ones fetched by this run, both most recently pushed first
+	var cached, fetched []Repository
+	if opts.SinceCache != nil {
+		cached = opts.SinceCache.Get(source)
+	}
+	previous := sinceCacheIndex(cached)
+
+	// the resume state is tied to the exact query and filters, so changing a flag starts over
+	var resumeHash string
+	resumed := false
+	if opts.Resume != nil {
+		hash, err := opts.Resume.queryHash(queryName, variables)
+		if err != nil {
+			return err
+		}
+		resumeHash = hash
+
+		if cursor := opts.Resume.cursor(source, resumeHash); cursor != "" {
+			log.Printf("[%s]: resuming after cursor %s\n", login, cursor)
+			variables["cursor"] = graphql.String(cursor)
+			resumed = true
+		}
+	}
+
+	// the first page may already be fetched by a batched query, unless resuming further
+	firstPage, prefetched := opts.FirstPages.get(source)
+	prefetched = prefetched && !resumed
+
+	// every source gets its own deadline, so a slow one doesn't eat the time of the others
+	ctx := context.Background()
+	if opts.SourceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.SourceTimeout)
+		defer cancel()
+	}
+
+	// pages are emitted concurrently with the fetch of the next page, so the network latency
+	// overlaps with the filters and the consumer (e.g. a slow pipe) instead of adding up
+	pages := make(chan fetchedPage, 1)
+	emitted := make(chan struct{})
+	// set when the emitting goroutine panicked, the pagination then stops at the next page
+	var emitErr atomic.Pointer[error]
+
+	// the source is only done once every fetched page reached the consumer. Deferred too, so a
+	// panic of the pagination loop (recovered by the caller) never leaves the goroutine running
+	// and nothing is emitted once this returns.
+	finishEmitting := sync.OnceFunc(func() {
+		close(pages)
+		<-emitted
+	})
+	defer finishEmitting()
+
+	go func() {
+		defer close(emitted)
+		defer func() {
+			if value := recover(); value != nil {
+				err := PanicError(source, value)
+				emitErr.Store(&err)
+
+				// keep draining so the pagination loop never blocks on a full channel
+				for range pages {
+				}
+			}
+		}()
+
+		for queued := range pages {
+			complete := opts.emit(client, source, queued.repositories, emitter)
+
+			// only recorded once emitted, so resuming never skips repositories that weren't printed
+			if opts.Resume != nil && queued.cursor != "" && complete {
+				if err := opts.Resume.setCursor(source, resumeHash, queued.cursor); err != nil {
+					log.Printf("Warning: [%s]: saving resume state: %v\n", login, err)
+				}
+			}
+		}
+	}()
+
+	var fetchErr error
+	// limited is set when the pagination stopped early, because the emitter is full, with FirstPageOnly or at MaxPages
+	limited := false
 	page := 1
+	// TotalCount of the first page and the number of repositories processed since
+	var totalCount, seen int
 
 	for {
-		log.Printf("[%s]: getting page %d...\n", org, page)
+		log.Printf("[%s]: getting page %d...\n", login, page)
 
-		err = client.Query("GetOrgRepositories", &query, variables)
-		if err != nil {
-			log.Fatal(err)
+		// the cursor variable only moves forward after a successful page,
+		// so retries resume from the last good cursor without losing progress
+		var repositories Repositories
+		if page == 1 && prefetched {
+			log.Printf("[%s]: page 1 fetched in a batch\n", login)
+			repositories = firstPage
+		} else {
+			query, err := queryWithRetry(ctx, client, queryName, newQuery, variables, opts, login, page)
+			if errors.Is(err, context.DeadlineExceeded) {
+				fetchErr = fmt.Errorf("getting page %d: %w after %s, %d repositories fetched", page, ErrSourceTimeout, opts.SourceTimeout, seen)
+				break
+			}
+			if err != nil {
+				fetchErr = fmt.Errorf("getting page %d: %w", page, unauthorized(sourceError(source, err)))
+				break
+			}
+
+			if opts.RateLimit != nil {
+				opts.RateLimit.Add(query.rateLimit())
+			}
+
+			repositories = query.repositories()
 		}
 
 		if page == 1 {
-			log.Printf("[%s]: has %d repos\n", org, query.Organization.Repositories.TotalCount)
+			log.Printf("[%s]: has %d repos\n", login, repositories.TotalCount)
+			totalCount = repositories.TotalCount
+
+			if opts.Progress != nil {
+				opts.Progress.SetTotal(source, repositories.TotalCount)
+			}
+		}
+
+		processed := len(repositories.Nodes)
+		unchanged := false
+
+		if opts.SinceCache != nil {
+			for i, repo := range repositories.Nodes {
+				// every repository from here on was pushed before the previous run
+				if cachedRepo, found := previous[repo.NameWithOwner]; found && cachedRepo.PushedAt.Equal(repo.PushedAt) {
+					processed = i
+					unchanged = true
+					break
+				}
+			}
+
+			fetched = append(fetched, repositories.Nodes[:processed]...)
 		}
 
-		for _, repo := range query.Organization.Repositories.Nodes {
-			// send repo line to channel
-			repoLinesChannel <- repo.Line()
+		nextPage := fetchedPage{repositories: repositories.Nodes[:processed]}
+		if repositories.PageInfo.HasNextPage {
+			nextPage.cursor = repositories.PageInfo.EndCursor
 		}
+		pages <- nextPage
 
-		if !query.Organization.Repositories.PageInfo.HasNextPage {
+		if unchanged {
+			served := sinceCacheRemainder(cached, fetched)
+			log.Printf("[%s]: %d repos unchanged since the previous run, served from cache\n", login, len(served))
+
+			pages <- fetchedPage{repositories: served}
+			processed += len(served)
+			fetched = append(fetched, served...)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress.Add(source, processed)
+		}
+		seen += processed
+
+		if unchanged || !repositories.PageInfo.HasNextPage {
+			// with some affiliation and privacy combinations the TotalCount includes repositories
+			// the viewer can't see, the progress (and the remembered total) is clamped to what was listed.
+			// A resumed source skipped the pages of the previous run, so it never adds up.
+			if seen < totalCount && !resumed {
+				log.Printf("[%s]: TotalCount %d but only %d repos visible, clamping the progress\n", login, totalCount, seen)
+
+				if opts.Progress != nil {
+					opts.Progress.SetTotal(source, seen)
+				}
+			}
+
+			break
+		}
+
+		if emitErr.Load() != nil {
+			break
+		}
+
+		// The emitter lags a page behind, so at most one page more than needed is fetched.
+		// Like at -max-pages the total isn't clamped, the next run should estimate the full TotalCount.
+		if emitter.Full() {
+			log.Printf("[%s]: limit of repositories (or of the source) reached, stopping at page %d\n", login, page)
+			limited = true
+			break
+		}
+
+		if opts.FirstPageOnly {
+			// the only clamped early stop, main doesn't remember the totals of -first-page-only runs
+			log.Printf("[%s]: first page only, results truncated to %d of %d repos\n", login, seen, totalCount)
+
+			if opts.Progress != nil {
+				opts.Progress.SetTotal(source, seen)
+			}
+
+			limited = true
 			break
 		}
 
-		variables["cursor"] = graphql.String(query.Organization.Repositories.PageInfo.EndCursor)
+		if opts.MaxPages > 0 && page >= opts.MaxPages {
+			log.Printf("Warning: [%s]: stopping at the maximum of %d pages, %d repos listed\n", login, opts.MaxPages, seen)
+			fmt.Fprintf(os.Stderr, "Warning: %s still had more pages after %d pages, stopped (see -max-pages)\n", source, opts.MaxPages)
+			limited = true
+			break
+		}
+
+		variables["cursor"] = graphql.String(repositories.PageInfo.EndCursor)
 		page += 1
+	}
 
+	finishEmitting()
+
+	if fetchErr != nil {
+		return fetchErr
+	}
+
+	if err := emitErr.Load(); err != nil {
+		return *err
+	}
+
+	// a partial listing is neither a complete cache entry nor a finished source
+	if limited {
+		return nil
+	}
+
+	if opts.SinceCache != nil {
+		opts.SinceCache.Put(source, fetched)
+	}
+
+	if opts.Resume != nil {
+		if err := opts.Resume.done(source); err != nil {
+			log.Printf("Warning: [%s]: saving resume state: %v\n", login, err)
+		}
 	}
 
 	return nil
 }
+
+// fetchedPage is a page of repositories handed from the pagination loop to the emitter
+type fetchedPage struct {
+	repositories []Repository
+	// cursor resuming after this page, empty for the last page
+	cursor string
+}
+
+// sinceCacheRemainder returns the cached repositories that were not fetched again
+func sinceCacheRemainder(cached, fetched []Repository) []Repository {
+	refetched := sinceCacheIndex(fetched)
+
+	var remainder []Repository
+	for _, repo := range cached {
+		if _, found := refetched[repo.NameWithOwner]; !found {
+			remainder = append(remainder, repo)
+		}
+	}
+
+	return remainder
+}
+
+// emit sends the repositories passing every filter through emitter, rendering happens
+// on the consumer side. It reports false when the limit cut the repositories short.
+func (opts Options) emit(client GraphQLClient, source Source, repos []Repository, emitter *Emitter) bool {
+	var kept []Repository
+	for _, repo := range repos {
+		// normalized before filtering so filters and every output see the same topics
+		if opts.NormalizeTopics {
+			repo = repo.normalizeTopics()
+		}
+
+		if opts.keep(repo) {
+			kept = append(kept, repo)
+		}
+	}
+
+	// the collaborator check costs a query per repository so it only runs on the ones left
+	if opts.Collaborator != nil {
+		kept = opts.Collaborator.filter(client, opts.RateLimit, kept)
+	}
+
+	for _, repo := range kept {
+		if !emitter.Emit(Result{Source: source, Repository: repo}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Renamed reports whether the repository fetched for the requested "owner/name" has another
+// name now, GitHub resolves the former names of renamed and transferred repositories
+func Renamed(requested string, repo Repository) bool {
+	return !strings.EqualFold(requested, repo.NameWithOwner)
+}
+
+// GetRepository fetches a single repository by its "owner/name"
+func GetRepository(client GraphQLClient, nameWithOwner string) (Repository, error) {
+	// a single repository is cheap so all optional fields are requested, and its owner
+	return getRepository(client, nameWithOwner, OptionalFields, DefaultMaxTopics, true)
+}
+
+// getRepository fetches a single repository by its "owner/name" with the given optional fields
+// and up to maxTopics topics, and the profile of its owner with withOwner
+func getRepository(client GraphQLClient, nameWithOwner string, fields []string, maxTopics int, withOwner bool) (Repository, error) {
+	owner, name, found := strings.Cut(nameWithOwner, "/")
+	if !found || owner == "" || name == "" {
+		return Repository{}, fmt.Errorf("invalid repository %q, expected owner/name", nameWithOwner)
+	}
+
+	var query GetRepositoryQuery
+	variables := fieldVariables(fields, maxTopics)
+	variables["withOwner"] = graphql.Boolean(withOwner)
+	variables["owner"] = graphql.String(owner)
+	variables["name"] = graphql.String(name)
+
+	log.Printf("[%s]: getting repository...\n", nameWithOwner)
+
+	if err := client.Query("GetRepository", &query, variables); err != nil {
+		// some fields of archived (or otherwise read-only) repositories can fail to resolve,
+		// the data of the others is still decoded and good enough to preview or clone it
+		var graphQLErr *api.GraphQLError
+		if !errors.As(err, &graphQLErr) || query.Repository.NameWithOwner == "" {
+			return Repository{}, err
+		}
+
+		log.Printf("Warning: [%s]: partial repository data: %v\n", nameWithOwner, err)
+	}
+
+	// renamed and transferred repositories are resolved to their current name
+	if Renamed(nameWithOwner, query.Repository) {
+		log.Printf("[%s]: redirected to %s\n", nameWithOwner, query.Repository.NameWithOwner)
+	}
+
+	return query.Repository, nil
+}
@@ -0,0 +1,143 @@
+package github
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryResumesFromTheLastCursor(t *testing.T) {
+	client := newFakeClient()
+	client.addOwner("acme", 250)
+	client.fail = func(login string, page, attempt int) error {
+		if page == 2 && attempt == 1 {
+			return httpError(http.StatusBadGateway)
+		}
+		return nil
+	}
+
+	opts := Options{Client: client, PageSize: 100, Retries: 3, RetryDelay: time.Millisecond}
+	results, err := collect(t, 0, func(emitter *Emitter) error {
+		return ProcessOrgRepositories("acme", opts, emitter)
+	})
+	if err != nil {
+		t.Fatalf("ProcessOrgRepositories: %v", err)
+	}
+
+	want := repoNames(client.owners["acme"])
+	if got := names(results); !slices.Equal(got, want) {
+		t.Errorf("emitted %d repositories, want each of the %d exactly once in order", len(got), len(want))
+	}
+
+	// pages 1 and 3 once, page 2 failed then succeeded
+	if queries := client.queryCount("acme"); queries != 4 {
+		t.Errorf("sent %d queries, want 4", queries)
+	}
+}
+
+// recordingProgress records the last total and the completion of every source
+type recordingProgress struct {
+	mu      sync.Mutex
+	totals  map[Source]int
+	fetched map[Source]int
+	done    map[Source]bool
+}
+
+func newRecordingProgress() *recordingProgress {
+	return &recordingProgress{totals: map[Source]int{}, fetched: map[Source]int{}, done: map[Source]bool{}}
+}
+
+func (p *recordingProgress) SetTotal(source Source, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.totals[source] = total
+}
+
+func (p *recordingProgress) Add(source Source, fetched int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetched[source] += fetched
+}
+
+func (p *recordingProgress) Done(source Source) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[source] = true
+}
+
+func TestTotalCountLargerThanTheVisibleRepositories(t *testing.T) {
+	client := newFakeClient()
+	client.addOwner("acme", 120)
+	client.totalCount["acme"] = 500
+
+	progress := newRecordingProgress()
+	opts := Options{Client: client, PageSize: 50, Progress: progress}
+	results, err := collect(t, 0, func(emitter *Emitter) error {
+		return ProcessOrgRepositories("acme", opts, emitter)
+	})
+	if err != nil {
+		t.Fatalf("ProcessOrgRepositories: %v", err)
+	}
+
+	if len(results) != 120 {
+		t.Errorf("emitted %d repositories, want 120", len(results))
+	}
+
+	source := Source{Kind: SourceOrg, Login: "acme"}
+	if progress.totals[source] != 120 || progress.fetched[source] != 120 || !progress.done[source] {
+		t.Errorf("progress at %d of %d (done %t), want clamped to 120 of 120 and done",
+			progress.fetched[source], progress.totals[source], progress.done[source])
+	}
+}
+
+func TestMaxPagesStopsAnEndlessPagination(t *testing.T) {
+	client := newFakeClient()
+	client.addOwner("acme", 10)
+	client.endless = true
+
+	opts := Options{Client: client, PageSize: 5, MaxPages: 3}
+	results, err := collect(t, 0, func(emitter *Emitter) error {
+		return ProcessOrgRepositories("acme", opts, emitter)
+	})
+	if err != nil {
+		t.Fatalf("ProcessOrgRepositories: %v", err)
+	}
+
+	if queries := client.queryCount("acme"); queries != 3 {
+		t.Errorf("sent %d queries, want the cap of 3", queries)
+	}
+
+	if want := repoNames(client.owners["acme"]); !slices.Equal(names(results), want) {
+		t.Errorf("emitted %v, want %v", names(results), want)
+	}
+}
+
+// BenchmarkPaginationWithSlowConsumer lists 250 repositories in pages of 50 answered in
+// 200ms each into a consumer taking 4ms per repository. One after the other it would take
+// the 1s of the pages plus the 1s of the consumer, fetching the next page while the
+// previous one is consumed brings it down to about 1.3s.
+func BenchmarkPaginationWithSlowConsumer(b *testing.B) {
+	client := newFakeClient()
+	client.addOwner("acme", 250)
+	client.latency = 200 * time.Millisecond
+
+	opts := Options{Client: client, PageSize: 50}
+	for b.Loop() {
+		results := make(chan Result)
+		done := make(chan error, 1)
+		go func() {
+			done <- ProcessOrgRepositories("acme", opts, NewEmitter(results, 0))
+			close(results)
+		}()
+
+		for range results {
+			time.Sleep(4 * time.Millisecond)
+		}
+
+		if err := <-done; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package github
+
+import "sync"
+
+// RateLimitUsage accumulates the GraphQL rate limit cost across all pages and
+// sources. It is safe for concurrent use by the producers.
+type RateLimitUsage struct {
+	mu      sync.Mutex
+	total   RateLimit
+	queries int
+}
+
+// Add records the rate limit reported by a single query
+func (u *RateLimitUsage) Add(rl RateLimit) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.queries++
+	cost := u.total.Cost + rl.Cost
+
+	// queries run concurrently so keep the lowest remaining budget seen
+	if u.queries == 1 || rl.Remaining < u.total.Remaining {
+		u.total = rl
+	}
+
+	u.total.Cost = cost
+}
+
+// Summary returns the accumulated cost together with the lowest remaining budget and its reset time
+func (u *RateLimitUsage) Summary() RateLimit {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.total
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// readmeBlob is a README file at the HEAD of the default branch
+type readmeBlob struct {
+	Blob struct {
+		// Text is empty for binary files
+		Text        string
+		IsTruncated bool
+	} `graphql:"... on Blob"`
+}
+
+// GetReadmeQuery tries the most common README names at once, the expressions are case-sensitive.
+// The objects are nil when the file doesn't exist.
+type GetReadmeQuery struct {
+	Repository struct {
+		Markdown      *readmeBlob `graphql:"markdown: object(expression: \"HEAD:README.md\")"`
+		LowerMarkdown *readmeBlob `graphql:"lowerMarkdown: object(expression: \"HEAD:readme.md\")"`
+		Plain         *readmeBlob `graphql:"plain: object(expression: \"HEAD:README\")"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// GetReadme returns the first lines of the README of a repository and whether it was cut,
+// either at lines or by GitHub for very large files. An empty text without error means the
+// repository has no README.
+func GetReadme(client GraphQLClient, nameWithOwner string, lines int) (string, bool, error) {
+	owner, name, found := strings.Cut(nameWithOwner, "/")
+	if !found || owner == "" || name == "" {
+		return "", false, fmt.Errorf("invalid repository %q, expected owner/name", nameWithOwner)
+	}
+
+	var query GetReadmeQuery
+	variables := map[string]any{
+		"owner": graphql.String(owner),
+		"name":  graphql.String(name),
+	}
+
+	log.Printf("[%s]: getting README...\n", nameWithOwner)
+
+	if err := client.Query("GetReadme", &query, variables); err != nil {
+		return "", false, err
+	}
+
+	for _, blob := range []*readmeBlob{query.Repository.Markdown, query.Repository.LowerMarkdown, query.Repository.Plain} {
+		if blob == nil || blob.Blob.Text == "" {
+			continue
+		}
+
+		text := strings.TrimRight(blob.Blob.Text, "\n")
+		all := strings.Split(text, "\n")
+		if len(all) > lines {
+			return strings.Join(all[:lines], "\n"), true, nil
+		}
+
+		return text, blob.Blob.IsTruncated, nil
+	}
+
+	return "", false, nil
+}
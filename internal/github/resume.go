@@ -0,0 +1,122 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ResumeState records the cursor of the last page fetched for every unfinished source,
+// so an interrupted run can be resumed from there. It is safe for concurrent use.
+type ResumeState struct {
+	mu   sync.Mutex
+	path string
+	// filters describes the client-side filters, the cursors are only valid for the same ones
+	filters string
+	sources map[string]resumeEntry
+}
+
+type resumeEntry struct {
+	// QueryHash identifies the query and variables the cursor belongs to
+	QueryHash string `json:"queryHash"`
+	Cursor    string `json:"cursor"`
+}
+
+// LoadResumeState reads the resume file at path, a missing file means nothing to resume.
+// filters describes the effective client-side filters (e.g. "exclude=acme/old"): the
+// pages skipped by a resumed run were printed with the filters of the previous run,
+// so changing them starts the sources over like changing the query does.
+func LoadResumeState(path, filters string) (*ResumeState, error) {
+	state := &ResumeState{path: path, filters: filters, sources: map[string]resumeEntry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &state.sources); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// cursor returns the cursor to resume source from, empty when the source has to start
+// over because it completed or was fetched with a different query
+func (s *ResumeState) cursor(source Source, queryHash string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, found := s.sources[source.String()]
+	if !found || entry.QueryHash != queryHash {
+		return ""
+	}
+
+	return entry.Cursor
+}
+
+// setCursor records the cursor after a successfully fetched page and saves the file
+func (s *ResumeState) setCursor(source Source, queryHash, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sources[source.String()] = resumeEntry{QueryHash: queryHash, Cursor: cursor}
+	return s.save()
+}
+
+// done forgets a completed source so the next run fetches it from the start
+func (s *ResumeState) done(source Source) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sources, source.String())
+	return s.save()
+}
+
+func (s *ResumeState) save() error {
+	data, err := json.MarshalIndent(s.sources, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file and rename it, an interrupted run never leaves a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// queryHash identifies a query by its name, every variable except the cursor and the
+// client-side filters of the state
+func (s *ResumeState) queryHash(queryName string, variables map[string]any) (string, error) {
+	hashed := make(map[string]any, len(variables))
+	for name, value := range variables {
+		if name != "cursor" {
+			hashed[name] = value
+		}
+	}
+
+	// maps are marshalled with sorted keys so the hash is stable
+	data, err := json.Marshal(hashed)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(queryName+"\n"+s.filters+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeStartsOverWhenTheFiltersChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.json")
+
+	// interrupted runs a fetch failing at page 2, after recording the cursor of the first page
+	interrupted := func(filters string) {
+		t.Helper()
+
+		client := newFakeClient()
+		client.addOwner("acme", 250)
+		client.fail = func(login string, page, attempt int) error {
+			if page == 2 {
+				return httpError(http.StatusBadGateway)
+			}
+			return nil
+		}
+
+		resume, err := LoadResumeState(path, filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := Options{Client: client, PageSize: 100, Resume: resume}
+		results, err := collect(t, 0, func(emitter *Emitter) error {
+			return ProcessOrgRepositories("acme", opts, emitter)
+		})
+		if err == nil || len(results) != 100 {
+			t.Fatalf("interrupted run: %d repositories and error %v, want the first page and an error", len(results), err)
+		}
+	}
+
+	// resumed returns the names of the repositories fetched by the next run
+	resumed := func(filters string) []string {
+		t.Helper()
+
+		client := newFakeClient()
+		client.addOwner("acme", 250)
+
+		resume, err := LoadResumeState(path, filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := Options{Client: client, PageSize: 100, Resume: resume}
+		results, err := collect(t, 0, func(emitter *Emitter) error {
+			return ProcessOrgRepositories("acme", opts, emitter)
+		})
+		if err != nil {
+			t.Fatalf("resumed run: %v", err)
+		}
+
+		return names(results)
+	}
+
+	interrupted("exclude=acme/old")
+	if got := resumed("exclude=acme/old"); len(got) != 150 || got[0] != "acme/repo-100" {
+		t.Errorf("same filters: fetched %d repositories, want the 150 after the first page", len(got))
+	}
+
+	interrupted("exclude=acme/old")
+	if got := resumed("exclude=acme/old,acme/legacy"); len(got) != 250 {
+		t.Errorf("other filters: fetched %d repositories, want all 250 from the start", len(got))
+	}
+}
@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// defaultRetryDelay is the wait before the first retry, doubled on every following attempt
+const defaultRetryDelay = time.Second
+
+// isRetryable reports whether a failed query is a transient failure worth re-issuing
+func isRetryable(err error) bool {
+	// retrying with the same rejected token can't succeed
+	if isUnauthorized(err) {
+		return false
+	}
+
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// queryWithRetry runs the query and re-issues it with the same variables (and
+// therefore the same cursor) up to opts.Retries times on transient failures.
+// Once ctx is done neither the query nor the retries go on.
+func queryWithRetry(ctx context.Context, client GraphQLClient, queryName string, newQuery func() repositoriesQuery, variables map[string]any, opts Options, login string, page int) (repositoriesQuery, error) {
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		query := newQuery()
+		err := queryContext(ctx, client, queryName, query, variables)
+		if err == nil {
+			return query, nil
+		}
+
+		// a cancelled request looks like a network error, which would otherwise be retried
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if attempt >= opts.Retries || !isRetryable(err) {
+			return nil, err
+		}
+
+		log.Printf("Warning: [%s]: page %d failed (%v), retrying in %s (attempt %d of %d)...\n", login, page, err, delay, attempt+1, opts.Retries)
+		if opts.RetryLog != nil {
+			opts.RetryLog.record(login, page, attempt+1, delay, err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// RetryLog records every retried page in a dedicated file, one tab-separated
+// "time source page attempt backoff error" line per attempt. The file is only
+// created once a retry actually happens, and shared by all sources.
+type RetryLog struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewRetryLog returns a RetryLog appending to the file at path
+func NewRetryLog(path string) *RetryLog {
+	return &RetryLog{path: path}
+}
+
+// record appends a retry attempt, failing to write it only logs a warning
+func (l *RetryLog) record(login string, page int, attempt int, delay time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		file, openErr := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if openErr != nil {
+			log.Printf("Warning: opening retry log: %v", openErr)
+			return
+		}
+		l.file = file
+	}
+
+	line := fmt.Sprintf("%s\t%s\t%d\t%d\t%s\t%s\n", time.Now().Format(time.RFC3339), login, page, attempt, delay, strings.Join(strings.Fields(err.Error()), " "))
+	if _, writeErr := l.file.WriteString(line); writeErr != nil {
+		log.Printf("Warning: writing retry log: %v", writeErr)
+	}
+}
+
+// Close closes the file, if any retry was recorded
+func (l *RetryLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
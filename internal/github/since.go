@@ -0,0 +1,119 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// sinceCacheVersion is bumped whenever the cached Repository struct gains fields,
+// so repositories cached by an older version are fetched again
+const sinceCacheVersion = 12
+
+// pushedAtOrder sorts the repositories most recently pushed first, so the
+// pagination can stop at the first repository unchanged since the previous run
+var pushedAtOrder = &RepositoryOrder{Field: "PUSHED_AT", Direction: "DESC"}
+
+// RepositoryOrder is the GraphQL RepositoryOrder input type
+type RepositoryOrder struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+}
+
+// SinceCache keeps the repositories of every source from the previous run.
+// It is safe for concurrent use by the producers.
+type SinceCache struct {
+	mu   sync.Mutex
+	path string
+	file sinceCacheFile
+}
+
+type sinceCacheFile struct {
+	Version int `json:"version"`
+	// Key identifies the server-side options the repositories were fetched with
+	Key     string                  `json:"key"`
+	Sources map[string][]Repository `json:"sources"`
+}
+
+// LoadSinceCache reads the cache stored at path. A missing file, or one written
+// with a different key (e.g. other -fields), results in an empty cache.
+func LoadSinceCache(path, key string) (*SinceCache, error) {
+	cache := &SinceCache{path: path, file: sinceCacheFile{Version: sinceCacheVersion, Key: key, Sources: map[string][]Repository{}}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file sinceCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if file.Version != sinceCacheVersion {
+		log.Printf("Ignoring since cache %s: written by version %d instead of %d", path, file.Version, sinceCacheVersion)
+		return cache, nil
+	}
+
+	if file.Key != key {
+		log.Printf("Ignoring since cache %s: fetched with %q instead of %q", path, file.Key, key)
+		return cache, nil
+	}
+
+	if file.Sources != nil {
+		cache.file.Sources = file.Sources
+	}
+
+	return cache, nil
+}
+
+// Get returns the repositories of source fetched by the previous run, most recently pushed first
+func (c *SinceCache) Get(source Source) []Repository {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.file.Sources[source.String()]
+}
+
+// Put replaces the repositories of source
+func (c *SinceCache) Put(source Source, repositories []Repository) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.file.Sources[source.String()] = repositories
+}
+
+// Save writes the cache back to its file
+func (c *SinceCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(c.file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// sinceCacheIndex indexes the cached repositories of a source by name
+func sinceCacheIndex(repositories []Repository) map[string]Repository {
+	index := make(map[string]Repository, len(repositories))
+	for _, repo := range repositories {
+		index[repo.NameWithOwner] = repo
+	}
+
+	return index
+}
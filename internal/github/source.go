@@ -0,0 +1,43 @@
+package github
+
+import "fmt"
+
+type SourceKind string
+
+const (
+	SourceUser SourceKind = "user"
+	SourceOrg  SourceKind = "org"
+	// SourceOwner is a login resolved to a user or an organization when it's fetched
+	SourceOwner SourceKind = "owner"
+	// SourceNames is the list of repositories of Options.Names, fetched one by one
+	SourceNames SourceKind = "names"
+)
+
+// Source is a user or organization whose repositories are listed
+type Source struct {
+	Kind  SourceKind
+	Login string
+}
+
+func (s Source) String() string {
+	return fmt.Sprintf("%s:%s", s.Kind, s.Login)
+}
+
+// Result is a repository together with the source it was fetched from.
+// Producers only send raw results, all formatting happens on the consumer side.
+type Result struct {
+	Source     Source
+	Repository Repository
+	// Done marks the last result of a source, it carries no repository
+	Done bool
+}
+
+// Progress receives pagination updates from the producers
+type Progress interface {
+	// SetTotal is called with the TotalCount reported by the first page
+	SetTotal(source Source, total int)
+	// Add is called with the number of repositories of every fetched page
+	Add(source Source, fetched int)
+	// Done is called once the source finished, successfully or not
+	Done(source Source)
+}
@@ -0,0 +1,100 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+type ViewerQuery struct {
+	Viewer struct {
+		Login string
+	}
+}
+
+// TokenInfo is the account and the scopes of the token
+type TokenInfo struct {
+	Login string
+	// Scopes of a classic token, nil when the API reports none (fine-grained and GitHub App tokens,
+	// whose permissions can't be read back)
+	Scopes []string
+}
+
+// ScopeCheck is the result of checking one scope needed by the requested sources
+type ScopeCheck struct {
+	Scope string
+	// Reason is what the scope is needed for
+	Reason  string
+	Granted bool
+}
+
+// impliedScopes lists the scopes granting each checked scope
+var impliedScopes = map[string][]string{
+	"repo":     {"repo"},
+	"read:org": {"read:org", "write:org", "admin:org"},
+}
+
+// GetTokenInfo returns the login of the token with the GraphQL client and its scopes with a REST
+// request, the GraphQL API doesn't expose the X-OAuth-Scopes header
+func GetTokenInfo(client GraphQLClient, opts ClientOptions) (TokenInfo, error) {
+	var query ViewerQuery
+	if err := client.Query("Viewer", &query, nil); err != nil {
+		return TokenInfo{}, unauthorized(err)
+	}
+
+	rest, err := api.NewRESTClient(api.ClientOptions{
+		AuthToken: opts.AuthToken,
+		Host:      opts.Host,
+		Headers:   opts.Headers,
+		Transport: statusTransport{base: http.DefaultTransport},
+	})
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	resp, err := rest.Request(http.MethodGet, "user", nil)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("reading the token scopes: %w", unauthorized(err))
+	}
+	defer resp.Body.Close()
+
+	info := TokenInfo{Login: query.Viewer.Login}
+	if header, found := resp.Header["X-Oauth-Scopes"]; found {
+		info.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// CheckScopes returns the scopes the sources need to be listed completely: repo for the private
+// repositories and read:org for the private repositories of the organizations. Without them the
+// API doesn't fail, it silently lists only the public repositories.
+func CheckScopes(info TokenInfo, sources []Source) []ScopeCheck {
+	var orgs []string
+	for _, source := range sources {
+		if source.Kind == SourceOrg || source.Kind == SourceOwner {
+			orgs = append(orgs, source.String())
+		}
+	}
+
+	checks := []ScopeCheck{{Scope: "repo", Reason: "private repositories"}}
+	if len(orgs) > 0 {
+		checks = append(checks, ScopeCheck{Scope: "read:org", Reason: "private repositories of " + strings.Join(orgs, ", ")})
+	}
+
+	for i, check := range checks {
+		checks[i].Granted = slices.ContainsFunc(impliedScopes[check.Scope], func(scope string) bool {
+			return slices.Contains(info.Scopes, scope)
+		})
+	}
+
+	return checks
+}
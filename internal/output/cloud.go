@@ -0,0 +1,77 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/utils"
+)
+
+// cloudBarWidth is the width of the bar of the most common topic, the others are scaled down
+const cloudBarWidth = 40
+
+// topicCloudWriter counts the repositories per topic and prints the cloud on Flush
+type topicCloudWriter struct {
+	w      io.Writer
+	top    int
+	counts map[string]int
+}
+
+// NewTopicCloudWriter returns a Writer printing one "<topic> <count> <bar>" line per topic, most
+// common first, with a bar of # scaled to the count of the most common topic. Only the top most
+// common topics are printed when top > 0.
+func NewTopicCloudWriter(w io.Writer, top int) Writer {
+	return &topicCloudWriter{w: w, top: top, counts: make(map[string]int)}
+}
+
+func (cw *topicCloudWriter) Write(result github.Result) error {
+	for _, topic := range result.Repository.Topics() {
+		cw.counts[topic]++
+	}
+
+	return nil
+}
+
+func (cw *topicCloudWriter) Flush() error {
+	topics := make([]string, 0, len(cw.counts))
+	for topic := range cw.counts {
+		topics = append(topics, topic)
+	}
+
+	// most common topics first, alphabetically on ties so the output is stable
+	slices.SortFunc(topics, func(a, b string) int {
+		return cmp.Or(cmp.Compare(cw.counts[b], cw.counts[a]), cmp.Compare(a, b))
+	})
+
+	if cw.top > 0 && len(topics) > cw.top {
+		topics = topics[:cw.top]
+	}
+
+	if len(topics) == 0 {
+		return nil
+	}
+
+	widths := []int{0, 0}
+	for _, topic := range topics {
+		widths[0] = max(widths[0], utils.DisplayWidth(topic))
+		widths[1] = max(widths[1], len(strconv.Itoa(cw.counts[topic])))
+	}
+
+	highest := cw.counts[topics[0]]
+	for _, topic := range topics {
+		count := cw.counts[topic]
+		// every topic gets at least one #, even when it is dwarfed by the most common one
+		bar := strings.Repeat("#", max(1, count*cloudBarWidth/highest))
+
+		if _, err := fmt.Fprintln(cw.w, utils.AlignColumns([]string{topic, strconv.Itoa(count), bar}, widths)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// countWriter counts the emitted repositories per source and prints the totals on Flush.
+// Counting what is emitted (rather than the GraphQL totalCount) keeps client-side filters into account.
+type countWriter struct {
+	w       io.Writer
+	sources []github.Source
+	counts  map[github.Source]int
+	// excludeArchived counts the archived repositories apart, see NewCountWriter
+	excludeArchived bool
+	archived        map[github.Source]int
+}
+
+// NewCountWriter returns a Writer printing one "<source>\t<count>" line per source
+// in the given order followed by a "total\t<count>" line. With excludeArchived the
+// archived repositories are left out of the counts and reported in their own column:
+// "<source>\t<count> active\t<count> archived".
+func NewCountWriter(w io.Writer, sources []github.Source, excludeArchived bool) Writer {
+	return &countWriter{w: w, sources: sources, counts: make(map[github.Source]int), excludeArchived: excludeArchived, archived: make(map[github.Source]int)}
+}
+
+func (cw *countWriter) Write(result github.Result) error {
+	if cw.excludeArchived && result.Repository.IsArchived {
+		cw.archived[result.Source]++
+		return nil
+	}
+
+	cw.counts[result.Source]++
+	return nil
+}
+
+func (cw *countWriter) Flush() error {
+	total, archived := 0, 0
+	for _, source := range cw.sources {
+		total += cw.counts[source]
+		archived += cw.archived[source]
+
+		if err := cw.line(source.String(), cw.counts[source], cw.archived[source]); err != nil {
+			return err
+		}
+	}
+
+	return cw.line("total", total, archived)
+}
+
+// line prints the counts of a source (or the total)
+func (cw *countWriter) line(name string, count, archived int) error {
+	if cw.excludeArchived {
+		_, err := fmt.Fprintf(cw.w, "%s\t%d active\t%d archived\n", name, count, archived)
+		return err
+	}
+
+	_, err := fmt.Fprintf(cw.w, "%s\t%d\n", name, count)
+	return err
+}
+
+// ownerCountWriter counts the emitted repositories per owner and prints the counts on Flush
+type ownerCountWriter struct {
+	w      io.Writer
+	counts map[string]int
+}
+
+// NewOwnerCountWriter returns a Writer printing one "<owner>\t<count>" line per owner of the
+// emitted repositories, sorted by descending count. Owners come from "owner/name", so they also
+// break down the sources listing repositories of several owners (e.g. -stdin).
+func NewOwnerCountWriter(w io.Writer) Writer {
+	return &ownerCountWriter{w: w, counts: make(map[string]int)}
+}
+
+func (ow *ownerCountWriter) Write(result github.Result) error {
+	ow.counts[result.Repository.Owner()]++
+	return nil
+}
+
+func (ow *ownerCountWriter) Flush() error {
+	owners := make([]string, 0, len(ow.counts))
+	for owner := range ow.counts {
+		owners = append(owners, owner)
+	}
+
+	// most repositories first, alphabetically on ties so the output is stable
+	slices.SortFunc(owners, func(a, b string) int {
+		return cmp.Or(cmp.Compare(ow.counts[b], ow.counts[a]), cmp.Compare(a, b))
+	})
+
+	for _, owner := range owners {
+		if _, err := fmt.Fprintf(ow.w, "%s\t%d\n", owner, ow.counts[owner]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
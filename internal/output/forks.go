@@ -0,0 +1,58 @@
+package output
+
+import (
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// forkDedupeWriter collapses forks and their parents into a single entry before
+// passing the results on. It needs the whole result set, so nothing streams.
+type forkDedupeWriter struct {
+	next Writer
+	// preferForks keeps the forks and drops their parents instead
+	preferForks bool
+	results     []github.Result
+}
+
+// NewForkDedupeWriter returns a Writer dropping every fork whose parent is part of the
+// results, or every parent that has one of its forks in the results when preferForks is set.
+// The remaining results are written to next in their original order.
+func NewForkDedupeWriter(next Writer, preferForks bool) Writer {
+	return &forkDedupeWriter{next: next, preferForks: preferForks}
+}
+
+func (fw *forkDedupeWriter) Write(result github.Result) error {
+	fw.results = append(fw.results, result)
+	return nil
+}
+
+func (fw *forkDedupeWriter) Flush() error {
+	names := make(map[string]bool, len(fw.results))
+	// parents with at least one of their forks in the results
+	forked := make(map[string]bool)
+
+	for _, result := range fw.results {
+		names[result.Repository.NameWithOwner] = true
+
+		if parent := result.Repository.ParentName(); parent != "" {
+			forked[parent] = true
+		}
+	}
+
+	for _, result := range fw.results {
+		repo := result.Repository
+
+		if fw.preferForks && forked[repo.NameWithOwner] {
+			continue
+		}
+
+		if !fw.preferForks && names[repo.ParentName()] {
+			continue
+		}
+
+		if err := fw.next.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return fw.next.Flush()
+}
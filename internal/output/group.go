@@ -0,0 +1,110 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// GroupByKeys lists the supported keys of NewGroupWriter
+var GroupByKeys = []string{"language"}
+
+// noGroup is the group of the repositories without a value for the key (e.g. no primary language)
+const noGroup = "(none)"
+
+// groupWriter buffers the results and prints them under a "## <group>" header per group
+type groupWriter struct {
+	w      io.Writer
+	format string
+	opts   Options
+	groups map[string][]github.Result
+}
+
+// NewGroupWriter returns a Writer printing the repositories under markdown headers, one per
+// value of key ("## Go"), sorted by group then "owner/name". Every group is rendered with its
+// own format writer, so its columns are aligned within the group. The json formats print a
+// single document and can't be split into groups.
+func NewGroupWriter(w io.Writer, key, format string, opts Options) (Writer, error) {
+	if !slices.Contains(GroupByKeys, key) {
+		return nil, fmt.Errorf("unknown key %q, expected one of: %s", key, strings.Join(GroupByKeys, ", "))
+	}
+
+	if format == "json" || format == "ndjson" {
+		return nil, fmt.Errorf("the %s format can't be grouped", format)
+	}
+
+	// fail upfront on an unknown format rather than on the first group
+	if _, err := New(format, io.Discard, opts); err != nil {
+		return nil, err
+	}
+
+	return &groupWriter{w: w, format: format, opts: opts, groups: make(map[string][]github.Result)}, nil
+}
+
+func (gw *groupWriter) Write(result github.Result) error {
+	group := result.Repository.Language()
+	if group == "" {
+		group = noGroup
+	}
+
+	gw.groups[group] = append(gw.groups[group], result)
+	return nil
+}
+
+func (gw *groupWriter) Flush() error {
+	groups := make([]string, 0, len(gw.groups))
+	for group := range gw.groups {
+		groups = append(groups, group)
+	}
+
+	// alphabetically, with the repositories without a value last
+	slices.SortFunc(groups, func(a, b string) int {
+		return cmp.Or(cmp.Compare(boolRank(a == noGroup), boolRank(b == noGroup)), cmp.Compare(strings.ToLower(a), strings.ToLower(b)))
+	})
+
+	for i, group := range groups {
+		results := gw.groups[group]
+		slices.SortFunc(results, func(a, b github.Result) int {
+			return cmp.Compare(strings.ToLower(a.Repository.NameWithOwner), strings.ToLower(b.Repository.NameWithOwner))
+		})
+
+		separator := ""
+		if i > 0 {
+			separator = "\n"
+		}
+
+		if _, err := fmt.Fprintf(gw.w, "%s## %s\n\n", separator, group); err != nil {
+			return err
+		}
+
+		writer, err := New(gw.format, gw.w, gw.opts)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if err := writer.Write(result); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// boolRank orders false before true
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
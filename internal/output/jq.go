@@ -0,0 +1,22 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// ValidateJQ checks that a -jq expression parses and compiles, so a typo fails before
+// anything is fetched rather than once every repository was
+func ValidateJQ(expr string) error {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("parsing jq expression: %w", err)
+	}
+
+	if _, err := gojq.Compile(query); err != nil {
+		return fmt.Errorf("compiling jq expression: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"time"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// Repository is the object printed by the json and ndjson formats. It decouples the
+// output from the GraphQL query struct: field names and their order are part of the
+// output contract, new fields are only ever appended. Fields that were not fetched
+// (see -fields) are empty rather than omitted so every object has the same shape.
+type Repository struct {
+	NameWithOwner    string      `json:"name_with_owner"`
+	URL              string      `json:"url"`
+	SSHURL           string      `json:"ssh_url"`
+	IsArchived       bool        `json:"is_archived"`
+	IsFork           bool        `json:"is_fork"`
+	IsTemplate       bool        `json:"is_template"`
+	IsDisabled       bool        `json:"is_disabled"`
+	DefaultBranch    string      `json:"default_branch"`
+	PushedAt         *time.Time  `json:"pushed_at"`
+	Topics           []string    `json:"topics"`
+	Description      string      `json:"description"`
+	Language         string      `json:"language"`
+	LastCommit       *LastCommit `json:"last_commit"`
+	ViewerPermission string      `json:"viewer_permission"`
+	IsEmpty          bool        `json:"is_empty"`
+	HasIssuesEnabled bool        `json:"has_issues_enabled"`
+	HasWikiEnabled   bool        `json:"has_wiki_enabled"`
+	DiskUsage        int         `json:"disk_usage"`
+	License          string      `json:"license"`
+	StargazerCount   int         `json:"stargazer_count"`
+	ForkCount        int         `json:"fork_count"`
+	OpenIssues       int         `json:"open_issues"`
+	OpenPullRequests int         `json:"open_pull_requests"`
+	LatestRelease    *Release    `json:"latest_release"`
+	// Source is only set with -annotate-source, so it's the one field omitted when empty
+	Source    string     `json:"source,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// LastCommit is the last commit on the default branch
+type LastCommit struct {
+	CommittedDate time.Time `json:"committed_date"`
+	Author        string    `json:"author"`
+}
+
+// Release is the latest release of a repository
+type Release struct {
+	TagName   string    `json:"tag_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewRepository converts a fetched repository into its JSON representation
+func NewRepository(repo github.Repository) Repository {
+	r := Repository{
+		NameWithOwner:    repo.NameWithOwner,
+		URL:              repo.URL,
+		SSHURL:           repo.SSHURL,
+		IsArchived:       repo.IsArchived,
+		IsFork:           repo.IsFork,
+		IsTemplate:       repo.IsTemplate,
+		IsDisabled:       repo.IsDisabled,
+		DefaultBranch:    repo.DefaultBranch(),
+		Topics:           repo.Topics(),
+		Description:      repo.Description,
+		Language:         repo.Language(),
+		ViewerPermission: repo.ViewerPermission,
+		IsEmpty:          repo.IsEmpty,
+		HasIssuesEnabled: repo.HasIssuesEnabled,
+		HasWikiEnabled:   repo.HasWikiEnabled,
+		DiskUsage:        repo.DiskUsage,
+		License:          repo.License(),
+		StargazerCount:   repo.StargazerCount,
+		ForkCount:        repo.ForkCount,
+		OpenIssues:       repo.Issues.TotalCount,
+		OpenPullRequests: repo.PullRequests.TotalCount,
+	}
+
+	if !repo.PushedAt.IsZero() {
+		pushedAt := repo.PushedAt
+		r.PushedAt = &pushedAt
+	}
+
+	if !repo.UpdatedAt.IsZero() {
+		updatedAt := repo.UpdatedAt
+		r.UpdatedAt = &updatedAt
+	}
+
+	if commit := repo.LastCommit(); commit != nil {
+		r.LastCommit = &LastCommit{CommittedDate: commit.CommittedDate, Author: commit.Author.Name}
+	}
+
+	if release := repo.LatestRelease(); release != nil {
+		r.LatestRelease = &Release{TagName: release.TagName, CreatedAt: release.CreatedAt}
+	}
+
+	return r
+}
@@ -0,0 +1,193 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// fullRepository returns a repository with every field of the json formats set
+func fullRepository() github.Repository {
+	repo := github.Repository{
+		NameWithOwner:    "acme/tool",
+		URL:              "https://github.com/acme/tool",
+		SSHURL:           "git@github.com:acme/tool.git",
+		IsFork:           true,
+		HasIssuesEnabled: true,
+		DiskUsage:        512,
+		DefaultBranchRef: &github.Ref{Name: "main"},
+		PushedAt:         time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:        time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC),
+		ViewerPermission: "ADMIN",
+		LicenseInfo:      &github.License{SpdxID: "MIT"},
+		Description:      "A tool",
+		PrimaryLanguage:  &github.Language{Name: "Go"},
+		StargazerCount:   12,
+		ForkCount:        3,
+	}
+
+	repo.DefaultBranchRef.Target.Commit.CommittedDate = time.Date(2026, 9, 30, 8, 0, 0, 0, time.UTC)
+	repo.DefaultBranchRef.Target.Commit.Author.Name = "Ariel"
+	repo.Issues.TotalCount = 5
+	repo.PullRequests.TotalCount = 2
+	repo.Releases.Nodes = []github.Release{{TagName: "v1.2.0", CreatedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)}}
+
+	for _, topic := range []string{"go", "cli"} {
+		repo.RepositoryTopics.Nodes = append(repo.RepositoryTopics.Nodes, struct{ Topic struct{ Name string } }{})
+		repo.RepositoryTopics.Nodes[len(repo.RepositoryTopics.Nodes)-1].Topic.Name = topic
+	}
+
+	return repo
+}
+
+// wantJSON is the output contract of the json format: field names and their order only ever grow at the end
+const wantJSON = `[
+  {
+    "name_with_owner": "acme/tool",
+    "url": "https://github.com/acme/tool",
+    "ssh_url": "git@github.com:acme/tool.git",
+    "is_archived": false,
+    "is_fork": true,
+    "is_template": false,
+    "is_disabled": false,
+    "default_branch": "main",
+    "pushed_at": "2026-10-01T12:00:00Z",
+    "topics": [
+      "cli",
+      "go"
+    ],
+    "description": "A tool",
+    "language": "Go",
+    "last_commit": {
+      "committed_date": "2026-09-30T08:00:00Z",
+      "author": "Ariel"
+    },
+    "viewer_permission": "ADMIN",
+    "is_empty": false,
+    "has_issues_enabled": true,
+    "has_wiki_enabled": false,
+    "disk_usage": 512,
+    "license": "MIT",
+    "stargazer_count": 12,
+    "fork_count": 3,
+    "open_issues": 5,
+    "open_pull_requests": 2,
+    "latest_release": {
+      "tag_name": "v1.2.0",
+      "created_at": "2026-09-01T00:00:00Z"
+    },
+    "source": "org:acme",
+    "updated_at": "2026-10-02T12:00:00Z"
+  }
+]
+`
+
+func TestJSONShape(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := New("json", &buf, Options{Pretty: true, AnnotateSource: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := github.Source{Kind: github.SourceOrg, Login: "acme"}
+	if err := writer.Write(github.Result{Source: source, Repository: fullRepository()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != wantJSON {
+		t.Errorf("json output changed:\n%s\nwant:\n%s", got, wantJSON)
+	}
+}
+
+func TestNDJSONShapeWithoutOptionalFields(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := New("ndjson", &buf, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Write(github.Result{Repository: github.Repository{NameWithOwner: "acme/empty"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fields that were not fetched are empty rather than omitted, source is only set with AnnotateSource
+	want := `{"name_with_owner":"acme/empty","url":"","ssh_url":"","is_archived":false,"is_fork":false,"is_template":false,` +
+		`"is_disabled":false,"default_branch":"","pushed_at":null,"topics":[],"description":"","language":"","last_commit":null,` +
+		`"viewer_permission":"","is_empty":false,"has_issues_enabled":false,"has_wiki_enabled":false,"disk_usage":0,"license":"",` +
+		`"stargazer_count":0,"fork_count":0,"open_issues":0,"open_pull_requests":0,"latest_release":null,"updated_at":null}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("ndjson output changed:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNDJSONWithConcurrentProducers(t *testing.T) {
+	const producers, perProducer = 32, 100
+
+	results := make(chan github.Result)
+	emitter := github.NewEmitter(results, 0)
+
+	var wg sync.WaitGroup
+	for producer := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			source := github.Source{Kind: github.SourceOrg, Login: fmt.Sprintf("org-%d", producer)}
+			for i := range perProducer {
+				repo := fullRepository()
+				repo.NameWithOwner = fmt.Sprintf("%s/repo-%d", source.Login, i)
+				emitter.Emit(github.Result{Source: source, Repository: repo})
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// the single consumer, like main
+	var buf bytes.Buffer
+	writer, err := New("ndjson", &buf, Options{AnnotateSource: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for result := range results {
+		if err := writer.Write(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var repo Repository
+		if err := json.Unmarshal(scanner.Bytes(), &repo); err != nil {
+			t.Fatalf("corrupted line %q: %v", scanner.Text(), err)
+		}
+
+		if seen[repo.NameWithOwner] {
+			t.Errorf("%s printed twice", repo.NameWithOwner)
+		}
+		seen[repo.NameWithOwner] = true
+	}
+
+	if len(seen) != producers*perProducer {
+		t.Errorf("printed %d repositories, want %d", len(seen), producers*perProducer)
+	}
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// unknownLanguage is the bucket of the repositories without a primary language
+const unknownLanguage = "(unknown)"
+
+// languageStatsWriter counts the repositories per source and primary language and prints the counts on Flush
+type languageStatsWriter struct {
+	w       io.Writer
+	sources []github.Source
+	counts  map[github.Source]map[string]int
+}
+
+// NewLanguageStatsWriter returns a Writer printing one "<source>\t<language>\t<count>" line
+// per primary language of each source. Sources come in the given order, each with its
+// languages sorted by descending count, and the sources without repositories are left out.
+func NewLanguageStatsWriter(w io.Writer, sources []github.Source) Writer {
+	return &languageStatsWriter{w: w, sources: sources, counts: make(map[github.Source]map[string]int)}
+}
+
+func (lw *languageStatsWriter) Write(result github.Result) error {
+	language := result.Repository.Language()
+	if language == "" {
+		language = unknownLanguage
+	}
+
+	counts, found := lw.counts[result.Source]
+	if !found {
+		counts = make(map[string]int)
+		lw.counts[result.Source] = counts
+	}
+
+	counts[language]++
+	return nil
+}
+
+func (lw *languageStatsWriter) Flush() error {
+	for _, source := range lw.sources {
+		counts := lw.counts[source]
+
+		languages := make([]string, 0, len(counts))
+		for language := range counts {
+			languages = append(languages, language)
+		}
+
+		// most used languages first, alphabetically on ties so the output is stable
+		slices.SortFunc(languages, func(a, b string) int {
+			return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+		})
+
+		for _, language := range languages {
+			if _, err := fmt.Fprintf(lw.w, "%s\t%s\t%d\n", source, language, counts[language]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+func TestLanguageStatsPerSource(t *testing.T) {
+	acme := github.Source{Kind: github.SourceOrg, Login: "acme"}
+	user := github.Source{Kind: github.SourceUser, Login: "octocat"}
+	empty := github.Source{Kind: github.SourceOrg, Login: "empty"}
+
+	result := func(source github.Source, language string) github.Result {
+		var repo github.Repository
+		if language != "" {
+			repo.PrimaryLanguage = &github.Language{Name: language}
+		}
+		return github.Result{Source: source, Repository: repo}
+	}
+
+	var buf strings.Builder
+	writer := NewLanguageStatsWriter(&buf, []github.Source{user, empty, acme})
+	for _, r := range []github.Result{
+		result(acme, "Go"), result(user, "Lua"), result(acme, ""), result(acme, "Go"),
+		result(acme, "Rust"), result(user, "Go"), result(acme, "Rust"), result(acme, "Go"), result(user, "Lua"),
+	} {
+		if err := writer.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "user:octocat\tLua\t2\n" +
+		"user:octocat\tGo\t1\n" +
+		"org:acme\tGo\t3\n" +
+		"org:acme\tRust\t2\n" +
+		"org:acme\t(unknown)\t1\n"
+	if buf.String() != want {
+		t.Errorf("printed\n%s\nwant\n%s", buf.String(), want)
+	}
+}
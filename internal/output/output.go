@@ -0,0 +1,332 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/utils"
+	"github.com/cli/go-gh/v2/pkg/jq"
+)
+
+// Formats supported by New
+var Formats = []string{"line", "json", "ndjson", "tsv", "clone-cmd", "name"}
+
+// Options controls how repositories are rendered
+type Options struct {
+	Line github.LineOptions
+	// Host is the GitHub host used by the clone-cmd format, github.com when empty
+	Host string
+	// IncludeArchivedInClone keeps archived repositories in the clone-cmd format
+	IncludeArchivedInClone bool
+	// SplitOwner renders the owner and the repository name as separate aligned columns (line format only)
+	SplitOwner bool
+	// Pretty indents the json format
+	Pretty bool
+	// JQ is a jq expression applied to the JSON array of the json format, see ValidateJQ
+	JQ string
+	// AllowDuplicates keeps repeated names in the name format
+	AllowDuplicates bool
+	// DedupeCaseInsensitive compares the names case-insensitively when dropping the repeated
+	// ones of the name format, the first seen casing is printed
+	DedupeCaseInsensitive bool
+	// AnnotateSource appends the source of each repository (e.g. "org:acme") to the lines,
+	// or adds it as the source field of the json and ndjson formats
+	AnnotateSource bool
+}
+
+// Writer renders repositories into an output format. Streaming formats write
+// each repository as soon as it arrives while others buffer until Flush.
+// Writers are not safe for concurrent use: they are only called from the single
+// consumer goroutine, which is what keeps lines and JSON objects from interleaving.
+type Writer interface {
+	Write(result github.Result) error
+	Flush() error
+}
+
+// New returns a Writer for the given format
+func New(format string, w io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case "", "line":
+		return &lineWriter{w: w, opts: opts}, nil
+	case "json":
+		return &jsonWriter{w: w, pretty: opts.Pretty, annotateSource: opts.AnnotateSource, jq: opts.JQ}, nil
+	case "ndjson":
+		return &ndjsonWriter{encoder: json.NewEncoder(w), annotateSource: opts.AnnotateSource}, nil
+	case "tsv":
+		return &tsvWriter{w: w}, nil
+	case "clone-cmd":
+		return &cloneCmdWriter{w: w, opts: opts}, nil
+	case "name":
+		return &nameWriter{w: w, allowDuplicates: opts.AllowDuplicates, caseInsensitive: opts.DedupeCaseInsensitive, seen: map[string]bool{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// lineWriter prints the aligned, human (and fzf) friendly repository line
+type lineWriter struct {
+	w    io.Writer
+	opts Options
+	// buffered results when a column width needs to be known upfront
+	results []github.Result
+}
+
+// buffered reports whether the lines can only be printed once every repository is known
+func (lw *lineWriter) buffered() bool {
+	return lw.opts.SplitOwner || lw.opts.Line.ShowLanguage || lw.opts.Line.SortTopicsByFrequency
+}
+
+func (lw *lineWriter) Write(result github.Result) error {
+	if lw.buffered() {
+		lw.results = append(lw.results, result)
+		return nil
+	}
+
+	_, err := fmt.Fprintln(lw.w, lw.line(result, lw.opts.Line))
+	return err
+}
+
+// line renders the line of a result, followed by its source when annotated. The source is
+// the last field so the first one (used by fzf with {1}) is still the repository.
+func (lw *lineWriter) line(result github.Result, lineOpts github.LineOptions) string {
+	line := result.Repository.Line(lineOpts)
+	if lw.opts.AnnotateSource {
+		line += "  " + result.Source.String()
+	}
+
+	return line
+}
+
+func (lw *lineWriter) Flush() error {
+	if !lw.buffered() {
+		return nil
+	}
+
+	lineOpts := lw.opts.Line
+
+	if lw.opts.SplitOwner {
+		// group the repositories of each owner together, keeping the API order within an owner
+		slices.SortStableFunc(lw.results, func(a, b github.Result) int {
+			return strings.Compare(a.Repository.Owner(), b.Repository.Owner())
+		})
+
+		for _, result := range lw.results {
+			lineOpts.OwnerWidth = max(lineOpts.OwnerWidth, utils.DisplayWidth(result.Repository.Owner()))
+		}
+	}
+
+	if lineOpts.ShowLanguage {
+		for _, result := range lw.results {
+			lineOpts.NameWidth = max(lineOpts.NameWidth, utils.DisplayWidth(result.Repository.NameColumn(lineOpts)))
+			lineOpts.LanguageWidth = max(lineOpts.LanguageWidth, utils.DisplayWidth(result.Repository.Language()))
+		}
+	}
+
+	if lineOpts.SortTopicsByFrequency {
+		lineOpts.TopicFrequency = make(map[string]int)
+		for _, result := range lw.results {
+			for _, topic := range result.Repository.Topics() {
+				lineOpts.TopicFrequency[topic]++
+			}
+		}
+	}
+
+	for _, result := range lw.results {
+		if _, err := fmt.Fprintln(lw.w, lw.line(result, lineOpts)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonWriter collects all repositories and prints them as a single JSON array
+type jsonWriter struct {
+	w              io.Writer
+	pretty         bool
+	annotateSource bool
+	jq             string
+	repos          []Repository
+}
+
+func (jw *jsonWriter) Write(result github.Result) error {
+	jw.repos = append(jw.repos, newAnnotatedRepository(result, jw.annotateSource))
+	return nil
+}
+
+func (jw *jsonWriter) Flush() error {
+	// print an empty array rather than null when nothing was found
+	if jw.repos == nil {
+		jw.repos = []Repository{}
+	}
+
+	indent := ""
+	if jw.pretty {
+		indent = "  "
+	}
+
+	if jw.jq != "" {
+		// the expression gets the same array that would be printed, like gh api --jq
+		data, err := json.Marshal(jw.repos)
+		if err != nil {
+			return err
+		}
+
+		return jq.EvaluateFormatted(bytes.NewReader(data), jw.w, jw.jq, indent, false)
+	}
+
+	encoder := json.NewEncoder(jw.w)
+	encoder.SetIndent("", indent)
+
+	return encoder.Encode(jw.repos)
+}
+
+// ndjsonWriter streams one JSON object per line as soon as each repository arrives
+type ndjsonWriter struct {
+	encoder        *json.Encoder
+	annotateSource bool
+}
+
+func (nw *ndjsonWriter) Write(result github.Result) error {
+	return nw.encoder.Encode(newAnnotatedRepository(result, nw.annotateSource))
+}
+
+// newAnnotatedRepository converts the repository of a result, with its source when annotated
+func newAnnotatedRepository(result github.Result, annotateSource bool) Repository {
+	repo := NewRepository(result.Repository)
+	if annotateSource {
+		repo.Source = result.Source.String()
+	}
+
+	return repo
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// tsvWriter prints one tab-separated record per repository with the columns:
+// name with owner, archived, fork, topics (comma-separated), HTTPS URL, SSH URL and description
+type tsvWriter struct {
+	w io.Writer
+}
+
+func (tw *tsvWriter) Write(result github.Result) error {
+	repo := result.Repository
+	fields := []string{
+		repo.NameWithOwner,
+		strconv.FormatBool(repo.IsArchived),
+		strconv.FormatBool(repo.IsFork),
+		strings.Join(repo.Topics(), ","),
+		repo.URL,
+		repo.SSHURL,
+		// keep the record on a single line
+		strings.Join(strings.Fields(repo.Description), " "),
+	}
+
+	_, err := fmt.Fprintln(tw.w, strings.Join(fields, "\t"))
+	return err
+}
+
+func (tw *tsvWriter) Flush() error {
+	return nil
+}
+
+// cloneCmdWriter prints a ready to run "gh repo clone" command per repository
+type cloneCmdWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func (cw *cloneCmdWriter) Write(result github.Result) error {
+	repo := result.Repository
+
+	// archived repositories are read-only, usually not worth cloning
+	if repo.IsArchived && !cw.opts.IncludeArchivedInClone {
+		return nil
+	}
+
+	target := repo.NameWithOwner
+	if cw.opts.Host != "" && cw.opts.Host != "github.com" {
+		target = cw.opts.Host + "/" + target
+	}
+
+	_, err := fmt.Fprintf(cw.w, "gh repo clone %s\n", target)
+	return err
+}
+
+func (cw *cloneCmdWriter) Flush() error {
+	return nil
+}
+
+// nameWriter prints the bare repository name, without its owner. Repositories with the
+// same name under different owners would print the same line, so only the first one is
+// printed unless duplicates are allowed.
+type nameWriter struct {
+	w               io.Writer
+	allowDuplicates bool
+	caseInsensitive bool
+	seen            map[string]bool
+}
+
+func (nw *nameWriter) Write(result github.Result) error {
+	// GitHub restricts names to ASCII letters, digits, ".", "-" and "_", but make sure
+	// a surprising one (e.g. from a GHES instance) can't break the one name per line output
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, result.Repository.Name())
+
+	if !nw.allowDuplicates {
+		key := name
+		if nw.caseInsensitive {
+			key = strings.ToLower(key)
+		}
+
+		if nw.seen[key] {
+			return nil
+		}
+		nw.seen[key] = true
+	}
+
+	_, err := fmt.Fprintln(nw.w, name)
+	return err
+}
+
+func (nw *nameWriter) Flush() error {
+	return nil
+}
+
+// nulWriter terminates the entries with a NUL byte instead of a newline
+type nulWriter struct {
+	w io.Writer
+}
+
+// NewNulWriter returns an io.Writer replacing the trailing newline of every write with a
+// NUL byte, for xargs -0 and the like. The writers of the line based formats write one
+// entry per call, so newlines inside an entry (e.g. a description in a template) are kept.
+func NewNulWriter(w io.Writer) io.Writer {
+	return nulWriter{w: w}
+}
+
+func (nw nulWriter) Write(data []byte) (int, error) {
+	entry, found := bytes.CutSuffix(data, []byte("\n"))
+	if !found {
+		return nw.w.Write(data)
+	}
+
+	if _, err := nw.w.Write(append(entry[:len(entry):len(entry)], 0)); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
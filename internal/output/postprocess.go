@@ -0,0 +1,204 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cli/safeexec"
+)
+
+// Postprocessor is an io.Writer piping every output line through an external command:
+// the line is the stdin of the command and its stdout replaces the line. Up to jobs
+// commands run at the same time while the lines keep their order.
+type Postprocessor struct {
+	w       io.Writer
+	shell   string
+	command string
+	// slots bounds the number of running commands
+	slots chan struct{}
+	// pending holds the result of each line in output order
+	pending chan chan []byte
+	partial []byte
+	done    chan struct{}
+}
+
+// NewPostprocessor returns a Postprocessor running command with sh, at most jobs at a time.
+// Close must be called to wait for the last lines.
+func NewPostprocessor(w io.Writer, command string, jobs int) (*Postprocessor, error) {
+	shell, err := safeexec.LookPath("sh")
+	if err != nil {
+		return nil, fmt.Errorf("running %q needs sh: %w", command, err)
+	}
+
+	jobs = max(jobs, 1)
+	p := &Postprocessor{
+		w:       w,
+		shell:   shell,
+		command: command,
+		slots:   make(chan struct{}, jobs),
+		pending: make(chan chan []byte, jobs),
+		done:    make(chan struct{}),
+	}
+
+	go p.print()
+
+	return p, nil
+}
+
+// Write starts the command of every complete line, the last partial line waits for the next Write or Close
+func (p *Postprocessor) Write(data []byte) (int, error) {
+	p.partial = append(p.partial, data...)
+
+	for {
+		line, rest, found := bytes.Cut(p.partial, []byte("\n"))
+		if !found {
+			break
+		}
+
+		p.start(string(line))
+		p.partial = rest
+	}
+
+	return len(data), nil
+}
+
+// Close processes the last partial line and waits until every line was written
+func (p *Postprocessor) Close() error {
+	if len(p.partial) > 0 {
+		p.start(string(p.partial))
+		p.partial = nil
+	}
+
+	close(p.pending)
+	<-p.done
+
+	return nil
+}
+
+// start runs the command for the line once a slot is free
+func (p *Postprocessor) start(line string) {
+	p.slots <- struct{}{}
+
+	result := make(chan []byte, 1)
+	p.pending <- result
+
+	go func() {
+		defer func() { <-p.slots }()
+		result <- p.run(line)
+	}()
+}
+
+// run returns the replacement of the line. A failing command passes the line through
+// unchanged with a warning, so one bad line doesn't drop a repository from the output.
+func (p *Postprocessor) run(line string) []byte {
+	cmd := exec.Command(p.shell, "-c", p.command)
+	cmd.Stdin = strings.NewReader(line + "\n")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	replacement, err := cmd.Output()
+	if err != nil {
+		log.Printf("Warning: -postprocess failed for %q: %v: %s", line, err, strings.TrimSpace(stderr.String()))
+		fmt.Fprintf(os.Stderr, "Warning: -postprocess failed for %q (%v), printing it unchanged\n", line, err)
+		return []byte(line + "\n")
+	}
+
+	// an empty output drops the line, otherwise make sure it stays one entry per line
+	if len(replacement) > 0 && !bytes.HasSuffix(replacement, []byte("\n")) {
+		replacement = append(replacement, '\n')
+	}
+
+	return replacement
+}
+
+// print writes the results in the order of their lines
+func (p *Postprocessor) print() {
+	defer close(p.done)
+
+	for result := range p.pending {
+		if _, err := p.w.Write(<-result); err != nil {
+			log.Printf("Error writing postprocessed line: %v", err)
+		}
+	}
+}
+
+// StreamPostprocessor is an io.Writer piping the whole output through a single long-lived
+// command, for line-oriented filters (e.g. sed -u or jq --unbuffered) printing a line per
+// line they read: the lines are written to its stdin as they arrive and its stdout becomes
+// the output. Starting the command once saves its startup cost on every line, but nothing
+// ties an output line to its input line, so a failure can't be handled per line.
+type StreamPostprocessor struct {
+	w       io.Writer
+	command string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	// failed is set once the command stopped reading, the next lines pass through unchanged
+	failed bool
+}
+
+// NewStreamPostprocessor starts command with sh, Close must be called to wait for its last lines
+func NewStreamPostprocessor(w io.Writer, command string) (*StreamPostprocessor, error) {
+	shell, err := safeexec.LookPath("sh")
+	if err != nil {
+		return nil, fmt.Errorf("running %q needs sh: %w", command, err)
+	}
+
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %q: %w", command, err)
+	}
+
+	return &StreamPostprocessor{w: w, command: command, cmd: cmd, stdin: stdin}, nil
+}
+
+// Write sends the data to the command, or to the output unchanged once the command stopped reading
+func (p *StreamPostprocessor) Write(data []byte) (int, error) {
+	if !p.failed {
+		if _, err := p.stdin.Write(data); err == nil {
+			return len(data), nil
+		}
+
+		// the command exited, wait for what it printed before passing the next lines through
+		p.failed = true
+		status := "exit status 0"
+		if err := p.wait(); err != nil {
+			status = err.Error()
+		}
+		log.Printf("Warning: -postprocess %q exited before the end of its input: %s", p.command, status)
+		fmt.Fprintf(os.Stderr, "Warning: -postprocess exited before the end of its input (%s), printing the remaining lines unchanged\n", status)
+	}
+
+	return p.w.Write(data)
+}
+
+// Close ends the input of the command and waits until it exits
+func (p *StreamPostprocessor) Close() error {
+	if p.failed {
+		return nil
+	}
+
+	if err := p.wait(); err != nil {
+		return fmt.Errorf("-postprocess %q: %w", p.command, err)
+	}
+
+	return nil
+}
+
+func (p *StreamPostprocessor) wait() error {
+	p.stdin.Close()
+	return p.cmd.Wait()
+}
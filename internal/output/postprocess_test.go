@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStreamPostprocessorRunsASingleCommand(t *testing.T) {
+	var buf strings.Builder
+	// NR numbers the lines read by the same process, a command per line would restart at 1
+	p, err := NewStreamPostprocessor(&buf, `awk '{ print NR ": " $0; fflush() }'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(p, "acme/repo-%d\n", i)
+		fmt.Fprintf(&want, "%d: acme/repo-%d\n", i, i)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != want.String() {
+		t.Errorf("printed\n%s\nwant\n%s", buf.String(), want.String())
+	}
+}
+
+func TestStreamPostprocessorReportsTheExitStatus(t *testing.T) {
+	var buf strings.Builder
+	p, err := NewStreamPostprocessor(&buf, "cat; exit 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprintln(p, "acme/repo")
+
+	if err := p.Close(); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Close() = %v, want the exit status of the command", err)
+	}
+
+	if buf.String() != "acme/repo\n" {
+		t.Errorf("printed %q, want the output of the command", buf.String())
+	}
+}
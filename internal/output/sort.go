@@ -0,0 +1,78 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// SortKeys lists the supported keys of NewSortWriter
+var SortKeys = []string{"name", "topic-count", "pushed"}
+
+// sortWriter orders the results before passing them on. It needs the whole result set, so nothing streams.
+type sortWriter struct {
+	next    Writer
+	compare func(a, b github.Repository) int
+	reverse bool
+	// limit keeps only the first results once sorted when > 0
+	limit   int
+	results []github.Result
+}
+
+// NewSortWriter returns a Writer passing the results to next ordered by key: "name" sorts by
+// "owner/name" (case-insensitive), "topic-count" by the number of topics, fewest first, then
+// by name, and "pushed" by the last push, most recent first. reverse reverses the whole order.
+// Only the first limit results are passed on when limit > 0.
+func NewSortWriter(next Writer, key string, reverse bool, limit int) (Writer, error) {
+	byName := func(a, b github.Repository) int {
+		return cmp.Compare(strings.ToLower(a.NameWithOwner), strings.ToLower(b.NameWithOwner))
+	}
+
+	var compare func(a, b github.Repository) int
+	switch key {
+	case "name":
+		compare = byName
+	case "topic-count":
+		compare = func(a, b github.Repository) int {
+			return cmp.Or(cmp.Compare(a.TopicCount(), b.TopicCount()), byName(a, b))
+		}
+	case "pushed":
+		compare = func(a, b github.Repository) int {
+			return cmp.Or(b.PushedAt.Compare(a.PushedAt), byName(a, b))
+		}
+	default:
+		return nil, fmt.Errorf("unknown key %q, expected one of: %s", key, strings.Join(SortKeys, ", "))
+	}
+
+	return &sortWriter{next: next, compare: compare, reverse: reverse, limit: limit}, nil
+}
+
+func (sw *sortWriter) Write(result github.Result) error {
+	sw.results = append(sw.results, result)
+	return nil
+}
+
+func (sw *sortWriter) Flush() error {
+	slices.SortStableFunc(sw.results, func(a, b github.Result) int {
+		if sw.reverse {
+			return sw.compare(b.Repository, a.Repository)
+		}
+
+		return sw.compare(a.Repository, b.Repository)
+	})
+
+	if sw.limit > 0 && len(sw.results) > sw.limit {
+		sw.results = sw.results[:sw.limit]
+	}
+
+	for _, result := range sw.results {
+		if err := sw.next.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return sw.next.Flush()
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/utils"
+)
+
+// summaryColumns are the headers of the summary table, the counts follow the same order
+var summaryColumns = []string{"source", "total", "archived", "forks", "empty", "no-topics", "no-description"}
+
+// sourceSummary counts the repositories of a source matching each summary column
+type sourceSummary struct {
+	total, archived, forks, empty, noTopics, noDescription int
+}
+
+// add counts the repository in every column it matches. Archived repositories are only
+// counted as archived with excludeArchived, the total and the other columns are then active only.
+func (s *sourceSummary) add(repo github.Repository, excludeArchived bool) {
+	if repo.IsArchived {
+		s.archived++
+		if excludeArchived {
+			return
+		}
+	}
+	s.total++
+	if repo.IsFork {
+		s.forks++
+	}
+	if repo.IsEmpty {
+		s.empty++
+	}
+	if repo.TopicCount() == 0 {
+		s.noTopics++
+	}
+	if repo.Description == "" {
+		s.noDescription++
+	}
+}
+
+// cells renders the counts as "<count> (<percentage>%)" cells. The archived repositories
+// aren't part of the total with excludeArchived, so they are rendered as "<count> excluded".
+func (s sourceSummary) cells(excludeArchived bool) []string {
+	cells := []string{strconv.Itoa(s.total)}
+	counts := []int{s.archived, s.forks, s.empty, s.noTopics, s.noDescription}
+	if excludeArchived {
+		cells = append(cells, fmt.Sprintf("%d excluded", s.archived))
+		counts = counts[1:]
+	}
+
+	for _, count := range counts {
+		percentage := 0
+		if s.total > 0 {
+			percentage = count * 100 / s.total
+		}
+		cells = append(cells, fmt.Sprintf("%d (%d%%)", count, percentage))
+	}
+
+	return cells
+}
+
+// summaryWriter aggregates the repositories of every source and prints the summary table on Flush
+type summaryWriter struct {
+	w         io.Writer
+	sources   []github.Source
+	summaries map[github.Source]*sourceSummary
+	// excludeArchived leaves the archived repositories out of the other columns
+	excludeArchived bool
+}
+
+// NewSummaryWriter returns a Writer printing an aligned table with the number (and percentage)
+// of archived, fork, empty, untagged and undescribed repositories per source, in the given
+// order, followed by a total row. With excludeArchived the archived repositories are only
+// counted in the archived column and the total column becomes the active column.
+func NewSummaryWriter(w io.Writer, sources []github.Source, excludeArchived bool) Writer {
+	summaries := make(map[github.Source]*sourceSummary)
+	for _, source := range sources {
+		summaries[source] = &sourceSummary{}
+	}
+
+	return &summaryWriter{w: w, sources: sources, summaries: summaries, excludeArchived: excludeArchived}
+}
+
+func (sw *summaryWriter) Write(result github.Result) error {
+	summary, found := sw.summaries[result.Source]
+	if !found {
+		summary = &sourceSummary{}
+		sw.summaries[result.Source] = summary
+	}
+
+	summary.add(result.Repository, sw.excludeArchived)
+	return nil
+}
+
+func (sw *summaryWriter) Flush() error {
+	header := slices.Clone(summaryColumns)
+	if sw.excludeArchived {
+		header[1] = "active"
+	}
+	rows := [][]string{header}
+
+	var total sourceSummary
+	for _, source := range sw.sources {
+		summary := sw.summaries[source]
+		rows = append(rows, append([]string{source.String()}, summary.cells(sw.excludeArchived)...))
+
+		total.total += summary.total
+		total.archived += summary.archived
+		total.forks += summary.forks
+		total.empty += summary.empty
+		total.noTopics += summary.noTopics
+		total.noDescription += summary.noDescription
+	}
+	rows = append(rows, append([]string{"total"}, total.cells(sw.excludeArchived)...))
+
+	widths := make([]int, len(summaryColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utils.DisplayWidth(cell))
+		}
+	}
+
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(sw.w, utils.AlignColumns(row, widths)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
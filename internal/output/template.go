@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// templateEscapes lets templates passed on the command line use \t and \n
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// templateWriter renders every repository with a user provided text/template
+type templateWriter struct {
+	w        io.Writer
+	template *template.Template
+	buffer   bytes.Buffer
+}
+
+// templateFuncs are the helpers available to the templates on top of the text/template builtins
+var templateFuncs = template.FuncMap{
+	// join takes the separator first so it can end a pipeline: {{.Topics | join ", "}}
+	"join": func(separator string, elements []string) string {
+		return strings.Join(elements, separator)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// NewTemplateWriter returns a Writer executing the text/template per repository, followed
+// by a newline. The template receives the github.Repository, so both its fields
+// (e.g. {{.NameWithOwner}}) and methods (e.g. {{.Topics}}) are available.
+// The template is parsed and executed against a sample repository upfront, so syntax
+// errors and unknown fields are reported before fetching.
+func NewTemplateWriter(w io.Writer, text string) (Writer, error) {
+	return newTemplateWriter(w, "output", templateEscapes.Replace(text))
+}
+
+// NewTemplateFileWriter is NewTemplateWriter with the template read from a file. Files can
+// use real tabs and newlines, so the escapes aren't replaced, and the newline ending the
+// file is dropped as one is printed after every repository anyway.
+func NewTemplateFileWriter(w io.Writer, path string) (Writer, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTemplateWriter(w, filepath.Base(path), strings.TrimSuffix(string(text), "\n"))
+}
+
+func newTemplateWriter(w io.Writer, name string, text string) (Writer, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.Execute(io.Discard, sampleRepository()); err != nil {
+		return nil, err
+	}
+
+	return &templateWriter{w: w, template: tmpl}, nil
+}
+
+func (tw *templateWriter) Write(result github.Result) error {
+	// render into a buffer first so a failing template doesn't print half a line
+	tw.buffer.Reset()
+	if err := tw.template.Execute(&tw.buffer, result.Repository); err != nil {
+		return err
+	}
+
+	tw.buffer.WriteByte('\n')
+	_, err := tw.w.Write(tw.buffer.Bytes())
+	return err
+}
+
+func (tw *templateWriter) Flush() error {
+	return nil
+}
+
+// sampleRepository returns a repository with every optional field set, so validating
+// a template doesn't trip over fields that are only nil for some repositories
+func sampleRepository() github.Repository {
+	repo := github.Repository{
+		NameWithOwner:    "owner/name",
+		DefaultBranchRef: &github.Ref{Name: "main"},
+		PrimaryLanguage:  &github.Language{Name: "Go"},
+		LicenseInfo:      &github.License{SpdxID: "MIT"},
+		PushedAt:         time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	repo.DefaultBranchRef.Target.Commit.CommittedDate = time.Now()
+	repo.Releases.Nodes = []github.Release{{TagName: "v1.0.0", CreatedAt: time.Now()}}
+
+	return repo
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// topicPair is a repository listed under one of its topics
+type topicPair struct {
+	topic         string
+	nameWithOwner string
+}
+
+// topicsExpandWriter collects a (topic, repository) pair per topic of every repository
+type topicsExpandWriter struct {
+	w     io.Writer
+	pairs []topicPair
+}
+
+// NewTopicsExpandWriter returns a Writer printing one "<topic>\t<owner/name>" line per topic
+// of every repository, sorted by topic then repository. Repositories without topics are left out.
+func NewTopicsExpandWriter(w io.Writer) Writer {
+	return &topicsExpandWriter{w: w}
+}
+
+func (tw *topicsExpandWriter) Write(result github.Result) error {
+	for _, topic := range result.Repository.Topics() {
+		tw.pairs = append(tw.pairs, topicPair{topic: topic, nameWithOwner: result.Repository.NameWithOwner})
+	}
+
+	return nil
+}
+
+func (tw *topicsExpandWriter) Flush() error {
+	slices.SortFunc(tw.pairs, func(a, b topicPair) int {
+		return cmp.Or(cmp.Compare(a.topic, b.topic), cmp.Compare(a.nameWithOwner, b.nameWithOwner))
+	})
+
+	for _, pair := range tw.pairs {
+		if _, err := fmt.Fprintf(tw.w, "%s\t%s\n", pair.topic, pair.nameWithOwner); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// topicColumnsWriter prints the topics of every repository as columns of their own
+type topicColumnsWriter struct {
+	w       io.Writer
+	columns int
+}
+
+// NewTopicColumnsWriter returns a Writer printing one tab-separated "<owner/name>\t<topic>..." record per
+// repository with exactly columns topic columns, the missing ones left blank, so every record has
+// the same number of cells when imported into a spreadsheet
+func NewTopicColumnsWriter(w io.Writer, columns int) Writer {
+	return &topicColumnsWriter{w: w, columns: columns}
+}
+
+func (tw *topicColumnsWriter) Write(result github.Result) error {
+	cells := make([]string, tw.columns+1)
+	cells[0] = result.Repository.NameWithOwner
+	copy(cells[1:], result.Repository.Topics())
+
+	_, err := fmt.Fprintln(tw.w, strings.Join(cells, "\t"))
+	return err
+}
+
+func (tw *topicColumnsWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+const barWidth = 30
+
+// Bar renders a single progress bar for all sources combined. The expected
+// total is the sum of the TotalCount reported by the first page of each source.
+// It is safe for concurrent use by the producers.
+type Bar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	sources  int
+	expected map[github.Source]int
+	fetched  map[github.Source]int
+	done     map[github.Source]bool
+}
+
+// New returns a Bar for the given number of sources writing to w (usually stderr)
+func New(w io.Writer, sources int) *Bar {
+	return &Bar{
+		w:        w,
+		sources:  sources,
+		expected: make(map[github.Source]int),
+		fetched:  make(map[github.Source]int),
+		done:     make(map[github.Source]bool),
+	}
+}
+
+func (b *Bar) SetTotal(source github.Source, total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expected[source] = total
+	b.render()
+}
+
+func (b *Bar) Add(source github.Source, fetched int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fetched[source] += fetched
+	b.render()
+}
+
+func (b *Bar) Done(source github.Source) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the TotalCount may not match what was actually fetched (failures, repositories
+	// hidden from the viewer), so a finished source counts as complete
+	b.expected[source] = b.fetched[source]
+	b.done[source] = true
+	b.render()
+}
+
+// Finish ends the progress line so following output starts on a new line
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fmt.Fprintln(b.w)
+}
+
+func (b *Bar) render() {
+	expected, fetched := 0, 0
+	for source, total := range b.expected {
+		expected += total
+		fetched += b.fetched[source]
+	}
+
+	ratio := 1.0
+	if expected > 0 {
+		ratio = min(float64(fetched)/float64(expected), 1)
+	}
+
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+
+	fmt.Fprintf(b.w, "\r[%s] %3.0f%% %d/%d repositories, %d/%d sources done", bar, ratio*100, fetched, expected, len(b.done), b.sources)
+}
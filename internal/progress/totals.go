@@ -0,0 +1,106 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// Totals remembers the TotalCount of every source between runs, so the progress
+// bar knows its denominator before the first page of a source arrives.
+// Counts are keyed by source and by the server-side filters they were reported for.
+type Totals struct {
+	mu     sync.Mutex
+	path   string
+	key    string
+	counts map[string]int
+}
+
+// LoadTotals reads the totals stored at path, a missing file means no estimates yet.
+// key identifies the server-side filters of the current run (e.g. -no-fork).
+func LoadTotals(path, key string) (*Totals, error) {
+	totals := &Totals{path: path, key: key, counts: map[string]int{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return totals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &totals.counts); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return totals, nil
+}
+
+func (t *Totals) entry(source github.Source) string {
+	return source.String() + " " + t.key
+}
+
+// Get returns the TotalCount reported for source by the previous run
+func (t *Totals) Get(source github.Source) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total, found := t.counts[t.entry(source)]
+	return total, found
+}
+
+// Save writes the totals back to their file
+func (t *Totals) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(t.counts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(t.path, data, 0644)
+}
+
+// Track returns a github.Progress recording the totals reported by the producers
+// before passing every update on to next (which may be nil)
+func (t *Totals) Track(next github.Progress) github.Progress {
+	return &totalsTracker{totals: t, next: next}
+}
+
+type totalsTracker struct {
+	totals *Totals
+	next   github.Progress
+}
+
+func (tt *totalsTracker) SetTotal(source github.Source, total int) {
+	tt.totals.mu.Lock()
+	tt.totals.counts[tt.totals.entry(source)] = total
+	tt.totals.mu.Unlock()
+
+	if tt.next != nil {
+		tt.next.SetTotal(source, total)
+	}
+}
+
+func (tt *totalsTracker) Add(source github.Source, fetched int) {
+	if tt.next != nil {
+		tt.next.Add(source, fetched)
+	}
+}
+
+func (tt *totalsTracker) Done(source github.Source) {
+	if tt.next != nil {
+		tt.next.Done(source)
+	}
+}
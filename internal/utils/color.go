@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"hash/fnv"
+	"strconv"
+)
+
+// colorPalette are the ANSI foreground colors of ColorByHash, readable on both dark and light
+// terminals: red, green, yellow, blue, magenta and cyan
+var colorPalette = []int{31, 32, 33, 34, 35, 36}
+
+// ColorByHash wraps s in the ANSI color picked by the FNV-1a hash of s, so the same string
+// always gets the same color across runs. DisplayWidth ignores the escape sequences.
+func ColorByHash(s string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(s))
+	color := colorPalette[hash.Sum32()%uint32(len(colorPalette))]
+
+	return "\x1b[" + strconv.Itoa(color) + "m" + s + "\x1b[0m"
+}
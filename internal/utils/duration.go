@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits are the day based suffixes time.ParseDuration doesn't support
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses a duration like "90d", "2w" or anything time.ParseDuration
+// accepts (e.g. "36h")
+func ParseDuration(s string) (time.Duration, error) {
+	value := strings.TrimSpace(s)
+
+	for suffix, unit := range durationUnits {
+		if number, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q, expected e.g. 90d, 2w or 36h", s)
+			}
+
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected e.g. 90d, 2w or 36h", s)
+	}
+
+	return duration, nil
+}
+
+// relativeUnits are the units of FormatRelative, largest first. Months and years are
+// approximated with 30 and 365 days, which is good enough to spot stale repositories.
+var relativeUnits = []struct {
+	suffix   string
+	duration time.Duration
+}{
+	{"y", 365 * 24 * time.Hour},
+	{"mo", 30 * 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+}
+
+// FormatRelative renders how long before now t is with its largest unit, e.g. "2d ago" or "3mo ago".
+// Less than a minute, and times after now (clock skew), are "just now".
+func FormatRelative(t, now time.Time) string {
+	elapsed := now.Sub(t)
+
+	for _, unit := range relativeUnits {
+		if elapsed >= unit.duration {
+			return strconv.FormatInt(int64(elapsed/unit.duration), 10) + unit.suffix + " ago"
+		}
+	}
+
+	return "just now"
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the supported size suffixes in kilobytes, largest first so "KB" doesn't shadow "MB"
+var sizeUnits = []struct {
+	suffix    string
+	kilobytes float64
+}{
+	{"GB", 1024 * 1024},
+	{"MB", 1024},
+	{"KB", 1},
+}
+
+// ParseSize parses a human size like "10MB", "1.5GB" or "512KB" (case-insensitive,
+// 1024 based) into kilobytes. A number without suffix is read as kilobytes.
+func ParseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1.0
+
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.kilobytes
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a number followed by KB, MB or GB", s)
+	}
+
+	return int(number * multiplier), nil
+}
+
+// FormatSize renders a size in kilobytes with the largest unit keeping it at least 1, e.g. "1.5 MB"
+func FormatSize(kilobytes int) string {
+	for _, unit := range sizeUnits {
+		if float64(kilobytes) >= unit.kilobytes && unit.kilobytes > 1 {
+			return strconv.FormatFloat(float64(kilobytes)/unit.kilobytes, 'f', 1, 64) + " " + unit.suffix
+		}
+	}
+
+	return strconv.Itoa(kilobytes) + " KB"
+}
@@ -1,12 +1,18 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/text"
+)
 
 // alignStrings aligns two strings with maximum padding between them,
 // up to a specified maxWidth. If the combined length of the strings
 // exceeds maxWidth, they are simply concatenated without padding.
+// Lengths are measured in display width so wide characters and
+// ANSI escape sequences don't break the alignment.
 func AlignStrings(s1, s2 string, maxWidth int) string {
-	totalLen := len(s1) + len(s2)
+	totalLen := text.DisplayWidth(s1) + text.DisplayWidth(s2)
 
 	if totalLen > maxWidth {
 		// Not enough space for padding, return concatenated strings
@@ -18,3 +24,35 @@ func AlignStrings(s1, s2 string, maxWidth int) string {
 
 	return s1 + padding + s2
 }
+
+// columnGap separates the columns rendered by AlignColumns
+const columnGap = "  "
+
+// AlignColumns renders cells as columns of the given display widths separated by
+// two spaces. Every cell but the last is padded to its width, cells wider than their
+// column are kept whole. AlignStrings then aligns the result against a right side.
+func AlignColumns(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			b.WriteString(columnGap)
+		}
+
+		if i < len(cells)-1 && i < len(widths) {
+			cell = PadRight(cell, widths[i])
+		}
+		b.WriteString(cell)
+	}
+
+	return b.String()
+}
+
+// PadRight pads s with spaces on the right up to the given display width
+func PadRight(s string, width int) string {
+	return text.PadRight(width, s)
+}
+
+// DisplayWidth returns the number of terminal columns s takes
+func DisplayWidth(s string) int {
+	return text.DisplayWidth(s)
+}
@@ -1,17 +1,82 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/arielschiavoni/gh-list-repos/internal/config"
 	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/output"
+	"github.com/arielschiavoni/gh-list-repos/internal/progress"
+	"github.com/arielschiavoni/gh-list-repos/internal/utils"
+	"github.com/cli/go-gh/v2/pkg/term"
 )
 
+// Exit codes, part of the documented contract scripts rely on
+const (
+	exitOK = 0
+	// exitUsage is returned for invalid flags and setup errors, before anything is fetched
+	exitUsage = 1
+	// exitPartialFailure is returned when some sources failed while others succeeded
+	exitPartialFailure = 2
+	// exitTotalFailure is returned when every source failed, or when -strict aborted the run
+	exitTotalFailure = 3
+)
+
+// headerFlags collects the repeated -header flags
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// sumCounts returns the total of the counts of every source
+func sumCounts(counts map[github.Source]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	return total
+}
+
+// sourceFailure records the error of a source that could not be fetched
+type sourceFailure struct {
+	Source github.Source
+	Err    error
+}
+
+// seenRepositories drops the repositories listed by several sources under different
+// casings (e.g. -owner acme and -orgs Acme), keyed by their lowercased owner/name
+type seenRepositories map[string]bool
+
+// duplicate reports whether repo was already seen, the first seen casing is the one printed
+func (s seenRepositories) duplicate(repo github.Repository) bool {
+	key := strings.ToLower(repo.NameWithOwner)
+	if s[key] {
+		return true
+	}
+
+	s[key] = true
+	return false
+}
+
 func main() {
 	// Use the standard log location in the user's home directory
 	homeDir, err := os.UserHomeDir()
@@ -42,102 +107,1045 @@ func main() {
 	// Add file and line number to log messages
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
+	// Subcommands define their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "clone":
+			os.Exit(runClone(os.Args[2:]))
+		case "open":
+			os.Exit(runOpen(os.Args[2:]))
+		case "preview":
+			os.Exit(runPreview(os.Args[2:]))
+		}
+	}
+
 	// Define flags
 	usernamePtr := flag.String("username", "", "GitHub username to fetch repositories from")
+	expandOrgsPtr := flag.Bool("expand-orgs", false, "Also fetches the repositories of every organization -username is a member of")
 	orgsPtr := flag.String("orgs", "", "Comma-separated list of GitHub organizations to fetch repositories from")
+	ownerPtr := flag.String("owner", "", "Comma-separated list of users or organizations, resolved automatically (one extra query each)")
 	noArchivedPtr := flag.Bool("no-archived", false, "Excludes archived repositories")
 	noForkPtr := flag.Bool("no-fork", false, "Excludes forked repositories")
+	noTemplatesPtr := flag.Bool("no-templates", false, "Excludes template repositories")
+	onlyTemplatesPtr := flag.Bool("only-templates", false, "Includes only template repositories")
+	noDisabledPtr := flag.Bool("no-disabled", false, "Excludes disabled repositories")
+	noEmptyPtr := flag.Bool("no-empty", false, "Excludes empty repositories (without any commit)")
+	// strings rather than bools to tell "not set" (no filter) apart from false
+	issuesEnabledPtr := flag.String("issues-enabled", "", "Includes only repositories with issues enabled (true) or disabled (false)")
+	wikiEnabledPtr := flag.String("wiki-enabled", "", "Includes only repositories with the wiki enabled (true) or disabled (false)")
+	largerThanPtr := flag.String("larger-than", "", "Includes only repositories larger than the given size on disk (e.g. 10MB, 1.5GB)")
+	smallerThanPtr := flag.String("smaller-than", "", "Includes only repositories smaller than the given size on disk (e.g. 512KB)")
+	licensePtr := flag.String("license", "", "Comma-separated list of SPDX license ids (e.g. MIT,Apache-2.0) to include, \"none\" matches unlicensed repositories")
+	releasedSincePtr := flag.String("released-since", "", "Includes only repositories with a release created within the given duration (e.g. 90d, 2w, 36h)")
+	includeNoReleasePtr := flag.Bool("include-no-release", false, "Keeps the repositories without any release when -released-since is set")
+	collaboratorPtr := flag.String("collaborator", "", "Includes only repositories the given user collaborates on (one extra query per repository)")
+	noTopicsPtr := flag.Bool("no-topics", false, "Includes only repositories without any topic")
+	hasTopicsPtr := flag.Bool("has-topics", false, "Includes only repositories with at least one topic")
+	minTopicsPtr := flag.Int("min-topics", 0, "Includes only repositories with at least the given number of topics")
+	nameFilterPtr := flag.String("name-filter", "", "Includes only repositories whose owner/name contains the given text (case-insensitive)")
+	matchDescriptionPtr := flag.String("match-description", "", "Includes only repositories whose description contains the given text (case-insensitive)")
+	matchModePtr := flag.String("match-mode", github.MatchAll, "How -name-filter and -match-description combine: all (both match) or any (either matches)")
+	queryPtr := flag.String("query", "", "Includes only repositories where any of -query-fields contains the given text (case-insensitive)")
+	queryFieldsPtr := flag.String("query-fields", github.QueryFieldName, "Comma-separated list of fields searched by -query: "+strings.Join(github.QueryFields, ", "))
+	excludePtr := flag.String("exclude", "", "Comma-separated list of owner/name repositories (or glob patterns like owner/*-archived) to exclude")
+	defaultBranchPtr := flag.String("default-branch", "", "Includes only repositories whose default branch has the given name")
+	stdinPtr := flag.Bool("stdin", false, "Reads owner/name lines (e.g. a list printed before) from stdin and fetches these repositories one by one instead of listing sources")
+	detectRenamePtr := flag.Bool("detect-rename", false, "Warns with an \"old -> new\" line about the -stdin repositories that were renamed or transferred")
+	fromFilePtr := flag.String("from-file", "", "Path to a file with one source per line (\"org:<name>\", \"user:<name>\" or a bare org name)")
+	outputPtr := flag.String("output", "", "Path to a file to write the results to instead of stdout")
+	formatPtr := flag.String("format", "line", "Output format: "+strings.Join(output.Formats, ", "))
+	outputTemplatePtr := flag.String("output-template", "", "Go text/template rendered per repository instead of -format, e.g. '{{.NameWithOwner}}\\t{{.URL}}'")
+	nameOnlyPtr := flag.Bool("name-only", false, "Shorthand for -format name, printing the repository names without their owner")
+	allowDuplicatesPtr := flag.Bool("allow-duplicates", false, "Keeps the repeated names of the name format (same name under different owners)")
+	dedupeCaseInsensitivePtr := flag.Bool("dedupe-case-insensitive", false, "Compares the logins of the sources, the owner/name of the repositories listed by several sources and the names of the name format case-insensitively when dropping duplicates (Owner and owner are the same), keeping the first seen casing")
+	jqPtr := flag.String("jq", "", "Filters the json format with a jq expression, like gh api --jq (e.g. '.[] | select(.is_fork) | .url')")
+	templateFilePtr := flag.String("template-file", "", "Path to a Go text/template file rendered per repository instead of -format, like -output-template")
+	prettyPtr := flag.Bool("pretty", false, "Indents the json format")
+	showURLPtr := flag.Bool("show-url", false, "Appends the repository URL to each line")
+	urlTypePtr := flag.String("url-type", "https", "URL shown by -show-url: https or ssh")
+	annotateSourcePtr := flag.Bool("annotate-source", false, "Appends the source each repository was fetched from (e.g. org:acme) to the end of its line, or adds it as a source field to the json and ndjson formats")
+	compactPtr := flag.Bool("compact", false, "Leaves the topics out of the lines, keeping the archived/fork markers (topics are still fetched for the filters)")
+	noPaddingPtr := flag.Bool("no-padding", false, "Separates the repository name from its details with a tab instead of aligning them with spaces")
+	splitOwnerPtr := flag.Bool("split-owner", false, "Shows the owner and the repository name as separate aligned columns, grouped by owner (disables streaming)")
+	includeArchivedInClonePtr := flag.Bool("include-archived-in-clone", false, "Keeps archived repositories in the clone-cmd format")
+	minPermissionPtr := flag.String("min-permission", "", "Includes only repositories where you have at least the given permission: "+strings.Join(github.Permissions, ", "))
+	showPermissionPtr := flag.Bool("show-permission", false, "Shows your permission on each repository")
+	showCountsPtr := flag.Bool("show-counts", false, "Shows the number of stars (★), forks (⑂), open issues (◎) and open pull requests (⇄) of each repository")
+	showLanguagePtr := flag.Bool("show-language", false, "Shows the primary language of each repository as an aligned column (disables streaming)")
+	showReleasePtr := flag.Bool("show-release", false, "Shows the tag and date of the latest release of each repository")
+	print0Ptr := flag.Bool("print0", false, "Terminates every entry with a NUL byte instead of a newline, for xargs -0 (not for the json formats)")
+	postprocessPtr := flag.String("postprocess", "", "Pipes every output line through an external command run with sh (the line on stdin, its stdout replaces it), e.g. 'tr a-z A-Z'")
+	postprocessStreamPtr := flag.Bool("postprocess-stream", false, "Runs the -postprocess command once for the whole output, writing the lines to its stdin as they arrive, instead of once per line")
+	colorTopicsByHashPtr := flag.Bool("color-topics-by-hash", false, "Colors every topic with a color derived from its name, the same topic always gets the same color (use fzf --ansi)")
+	noColorPtr := flag.Bool("no-color", false, "Disables the colors, like the NO_COLOR environment variable")
+	showUpdatedPtr := flag.Bool("show-updated", false, "Shows how long ago each repository was updated (e.g. 2d ago, 3mo ago)")
+	showLicensePtr := flag.Bool("show-license", false, "Shows the SPDX license id of each repository")
+	showSizePtr := flag.Bool("show-size", false, "Shows the size on disk of each repository")
+	showBranchPtr := flag.Bool("show-branch", false, "Shows the default branch of each repository")
+	showRateLimitPtr := flag.Bool("show-rate-limit", false, "Prints the GraphQL rate limit cost and remaining points to stderr after fetching")
+	sortTopicsByFrequencyPtr := flag.Bool("sort-topics-by-frequency", false, "Orders the topics of each line by how many listed repositories have them, most common first (disables streaming)")
+	normalizeTopicsPtr := flag.Bool("normalize-topics", false, "Lowercases, trims and de-duplicates the topic names")
+	noTopicsFetchPtr := flag.Bool("no-topics-fetch", false, "Never requests the topics, even when they are part of -fields, to make queries against huge organizations leaner")
+	fieldsPtr := flag.String("fields", github.FieldTopics, "Comma-separated list of optional fields to fetch: "+strings.Join(github.OptionalFields, ", "))
+	dryRunPtr := flag.Bool("dry-run", false, "Prints the GraphQL queries and variables to stderr instead of sending them")
+	validatePtr := flag.Bool("validate", false, "Checks that the token scopes can list the private repositories of the sources, then exits without fetching them")
+	hostPtr := flag.String("host", "", "GitHub host to fetch repositories from (default GH_HOST or the authenticated host)")
+	var headers headerFlags
+	flag.Var(&headers, "header", "Adds a key:value header to every request (repeatable), e.g. 'X-GitHub-Api-Version:2022-11-28'")
+	tokenPtr := flag.String("token", "", "GitHub token used instead of the gh authentication (default GH_TOKEN or GITHUB_TOKEN)")
+	verbosePtr := flag.Bool("verbose", false, "Mirrors the log output to stderr")
+	failFastOnAuthPtr := flag.Bool("fail-fast-on-auth", true, "Aborts as soon as the token is rejected (HTTP 401) instead of failing every source")
+	strictPtr := flag.Bool("strict", false, "Exits as soon as any source fails instead of continuing with the others")
+	dedupeForksPtr := flag.Bool("dedupe-forks", false, "Drops the forks whose parent repository is listed as well (disables streaming)")
+	preferForksPtr := flag.Bool("prefer-forks", false, "Drops the parent repositories that have one of their forks listed (disables streaming)")
+	interactivePtr := flag.Bool("interactive", false, "Lets you pick a repository from a numbered menu when writing to a terminal, printing its owner/name")
+	sortPtr := flag.String("sort", "", fmt.Sprintf("Prints the repositories ordered by the given key (one of: %s) instead of streaming them", strings.Join(output.SortKeys, ", ")))
+	recentPtr := flag.Int("recent", 0, "Prints only the given number of most recently pushed repositories across all sources, most recent first (a shortcut for -sort pushed keeping the top N)")
+	deterministicPtr := flag.Bool("deterministic", false, "Prints a byte-for-byte stable output across runs: sorts by name and fetches the sources one at a time (slower), like -sort name -max-concurrency 1")
+	maxConcurrencyPtr := flag.Int("max-concurrency", 0, "Fetches at most the given number of sources at the same time (0 fetches all of them at once)")
+	reversePtr := flag.Bool("reverse", false, "Reverses the order of -sort")
+	groupByPtr := flag.String("group-by", "", fmt.Sprintf("Prints the repositories under a markdown header per value of the given key (one of: %s), sorted by group then name", strings.Join(output.GroupByKeys, ", ")))
+	groupBySourcePtr := flag.Bool("group-by-source", false, "Prints the repositories grouped by source, in the order the sources were specified, instead of streaming them")
+	parallelSourcesOrderedPtr := flag.Bool("parallel-sources-ordered", false, "Prints the repositories grouped by source, each source as soon as it is done (in completion order)")
+	countOnlyPtr := flag.Bool("count-only", false, "Prints the number of repositories per source and the total instead of the repositories")
+	countByOwnerPtr := flag.Bool("count-by-owner", false, "Prints the number of repositories per owner, most repositories first, instead of the repositories")
+	bufferPtr := flag.Int("buffer", 0, "Number of repositories queued between the fetches and the output, so fetching continues while the output is blocked (e.g. a paused pipe)")
+	saveQueryPtr := flag.String("save-query", "", "Writes the effective value of every flag (command line, environment and config file, without the token) as JSON to the given path")
+	skipEmptySourcesPtr := flag.Bool("skip-empty-sources", true, "Silently skips the sources that listed no repositories (matching the filters), -skip-empty-sources=false reports them with a warning")
+	maxPagesPtr := flag.Int("max-pages", 1000, "Stops a source after the given number of pages with a warning, a safety cap against a never ending pagination (0 for no cap)")
+	firstPageOnlyPtr := flag.Bool("first-page-only", false, "Fetches only the first page (up to -page-size repositories) of each source, for a quick peek")
+	limitPtr := flag.Int("limit", 0, "Stops once the given number of repositories was listed across all sources (0 lists all)")
+	limitPerSourcePtr := flag.Int("limit-per-source", 0, "Lists at most the given number of repositories of each source (0 lists all), combined with -limit")
+	pageSizePtr := flag.Int("page-size", github.MaxPageSize, fmt.Sprintf("Number of repositories requested per page (1-%d)", github.MaxPageSize))
+	languageStatsPtr := flag.Bool("language-stats", false, "Prints the number of repositories per primary language of each source instead of the repositories")
+	summaryPtr := flag.Bool("summary", false, "Prints the number of archived, fork, empty, untagged and undescribed repositories per source instead of the repositories")
+	excludeArchivedFromCountPtr := flag.Bool("exclude-archived-from-count", false, "Leaves the archived repositories out of the -count-only and -summary counts, reporting them apart")
+	topicCloudPtr := flag.Bool("topic-cloud", false, "Prints the number of repositories per topic, most common first, with a bar scaled to the most common one, instead of the repositories")
+	topPtr := flag.Int("top", 0, "Prints only the given number of most common topics with -topic-cloud (0 prints all)")
+	topicsExpandPtr := flag.Bool("repo-topics-expand", false, "Prints a \"<topic>\t<owner/name>\" line per topic of every repository, sorted by topic, instead of the repositories")
+	topicsAsColumnsPtr := flag.Bool("topics-as-columns", false, "Prints a tab-separated \"<owner/name>\t<topic>...\" record per repository with one column per topic, -max-topics columns, for spreadsheets")
+	maxTopicsPtr := flag.Int("max-topics", github.DefaultMaxTopics, fmt.Sprintf("Number of topics fetched per repository (1-%d)", github.MaxTopics))
+	batchOrgsPtr := flag.Bool("batch-orgs", false, "Fetches the first page of up to "+strconv.Itoa(github.OrgBatchSize)+" organizations per GraphQL request")
+	retryLogPtr := flag.String("retry-log", "", "Path to a file recording every retried page (source, page, attempt, backoff and error), only created when a retry happens")
+	sourceTimeoutPtr := flag.Duration("source-timeout", 0, "Maximum time spent fetching each source (e.g. 30s), a source timing out keeps what it listed and the others continue (0 for no limit)")
+	retriesPtr := flag.Int("retries", 3, "Number of times a page is re-fetched after a transient failure (5xx, rate limit or network errors)")
+	progressPtr := flag.Bool("progress", false, "Shows a combined progress bar of all sources on stderr")
+	watchPtr := flag.Bool("watch", false, "Keeps fetching every -interval and prints the repositories added (+) or removed (-) since the previous run")
+	intervalPtr := flag.Duration("interval", 5*time.Minute, "Time between fetches in -watch mode")
+	sinceCachePtr := flag.Bool("since-cache", false, "Only fetches the repositories pushed since the previous -since-cache run and serves the others from the cache")
+	resumeFilePtr := flag.String("resume-file", "", "Path to a file recording the pagination progress of every source, so an interrupted run resumes where it stopped")
+	configPtr := flag.String("config", "", "Path to a YAML file with default flag values (default ~/.config/gh-list-repos/config.yaml)")
 
 	// Parse flags
 	flag.Parse()
 
+	// Fill in the flags that were not passed on the command line from the environment,
+	// Apply then counts them as set so the config file doesn't override them either
+	if err := config.Apply(flag.CommandLine, config.FromEnv(flag.CommandLine)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in %s environment variables: %v\n", config.EnvPrefix+"*", err)
+		os.Exit(exitUsage)
+	}
+
+	// Fill in the flags that were not passed on the command line from the config file
+	configFile := *configPtr
+	if configFile == "" {
+		configFile, err = config.DefaultPath()
+		if err != nil {
+			log.Fatalf("Failed to resolve config file path: %v", err)
+		}
+	}
+
+	configValues, err := config.Load(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := config.Apply(flag.CommandLine, configValues); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config file %s: %v\n", configFile, err)
+		os.Exit(exitUsage)
+	}
+
+	// Record how the list was generated once every override is applied
+	if *saveQueryPtr != "" {
+		if err := config.Save(flag.CommandLine, *saveQueryPtr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving the query: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	// Logs go to stderr as well, stdout stays reserved for the repositories
+	if *verbosePtr {
+		log.SetOutput(io.MultiWriter(logFile, os.Stderr))
+	}
+
 	username := *usernamePtr
 	orgString := *orgsPtr
+	fromFile := *fromFilePtr
 	noArchived := *noArchivedPtr
 	noFork := *noForkPtr
+	outputPath := *outputPtr
+	format := *formatPtr
+	if *nameOnlyPtr {
+		format = "name"
+	}
+	showURL := *showURLPtr
+	urlType := *urlTypePtr
+	showRateLimit := *showRateLimitPtr
+	dryRun := *dryRunPtr
+	strict := *strictPtr
+	groupBySource := *groupBySourcePtr
+	parallelSourcesOrdered := *parallelSourcesOrderedPtr
+	pageSize := *pageSizePtr
+
+	if pageSize < 1 {
+		fmt.Fprintf(os.Stderr, "Invalid -page-size %d, it must be at least 1\n", pageSize)
+		os.Exit(exitUsage)
+	}
+
+	if pageSize > github.MaxPageSize {
+		log.Printf("Warning: -page-size %d is larger than %d, using %d", pageSize, github.MaxPageSize, github.MaxPageSize)
+		fmt.Fprintf(os.Stderr, "Warning: -page-size %d is larger than %d, using %d\n", pageSize, github.MaxPageSize, github.MaxPageSize)
+		pageSize = github.MaxPageSize
+	}
+
+	if *maxTopicsPtr < 1 || *maxTopicsPtr > github.MaxTopics {
+		fmt.Fprintf(os.Stderr, "Invalid -max-topics %d, it must be between 1 and %d\n", *maxTopicsPtr, github.MaxTopics)
+		os.Exit(exitUsage)
+	}
+
+	token := *tokenPtr
+	if token == "" {
+		token = github.TokenFromEnv()
+	}
+
+	var fields []string
+	for _, field := range strings.Split(*fieldsPtr, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if !slices.Contains(github.OptionalFields, field) {
+			fmt.Fprintf(os.Stderr, "Invalid -fields value %q, expected any of: %s\n", field, strings.Join(github.OptionalFields, ", "))
+			os.Exit(exitUsage)
+		}
+
+		fields = append(fields, field)
+	}
+
+	if *showCountsPtr && !slices.Contains(fields, github.FieldCounts) {
+		fields = append(fields, github.FieldCounts)
+	}
+
+	if (*releasedSincePtr != "" || *showReleasePtr) && !slices.Contains(fields, github.FieldRelease) {
+		fields = append(fields, github.FieldRelease)
+	}
+
+	// -query only searches the optional fields when they are fetched
+	queryFields := strings.Split(*queryFieldsPtr, ",")
+	queryDescription := *queryPtr != "" && slices.Contains(queryFields, github.QueryFieldDescription)
+	queryTopics := *queryPtr != "" && slices.Contains(queryFields, github.QueryFieldTopics)
+
+	if (*matchDescriptionPtr != "" || *summaryPtr || queryDescription) && !slices.Contains(fields, github.FieldDescription) {
+		fields = append(fields, github.FieldDescription)
+	}
+
+	// The topic filters need the topics, even when they are not part of -fields
+	topicFilters := *noTopicsPtr || *hasTopicsPtr || *minTopicsPtr > 0
+	needsTopics := topicFilters || *topicsExpandPtr || *topicsAsColumnsPtr || *topicCloudPtr || *summaryPtr || queryTopics || *sortPtr == "topic-count"
+	if *noTopicsFetchPtr {
+		if needsTopics || *sortTopicsByFrequencyPtr {
+			fmt.Fprintln(os.Stderr, "-no-topics-fetch can't be combined with the topic filters, -repo-topics-expand, -topics-as-columns, -topic-cloud, -summary, -sort topic-count, -sort-topics-by-frequency or -query-fields topics")
+			os.Exit(exitUsage)
+		}
+
+		fields = slices.DeleteFunc(fields, func(field string) bool { return field == github.FieldTopics })
+	} else if needsTopics && !slices.Contains(fields, github.FieldTopics) {
+		fields = append(fields, github.FieldTopics)
+	}
+
+	if (*languageStatsPtr || *showLanguagePtr || *groupByPtr == "language") && !slices.Contains(fields, github.FieldLanguage) {
+		fields = append(fields, github.FieldLanguage)
+	}
+
+	// The client needs the authentication, so it is only created once the flags are valid and a
+	// run without sources still prints the usage. -expand-orgs needs it to build the sources.
+	var client github.GraphQLClient
+	var requestHeaders map[string]string
+	newClient := func() {
+		requestHeaders, err = github.ParseHeaders(headers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -header: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		client, err = github.NewClient(github.ClientOptions{AuthToken: token, Host: *hostPtr, DryRun: dryRun, DryRunOutput: os.Stderr, Headers: requestHeaders})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	if *expandOrgsPtr && username == "" {
+		fmt.Fprintln(os.Stderr, "-expand-orgs needs -username")
+		os.Exit(exitUsage)
+	}
+
+	var sources []github.Source
+	if username != "" {
+		sources = append(sources, github.Source{Kind: github.SourceUser, Login: username})
+	}
+
+	// The organizations are resolved upfront so they are regular sources for the counts, progress and summary
+	if *expandOrgsPtr {
+		newClient()
+
+		orgs, err := github.GetUserOrganizations(client, username)
+		if err != nil {
+			// the user source fails on its own and is reported with the others
+			log.Printf("Warning: expanding the organizations of %s: %v", username, err)
+			fmt.Fprintf(os.Stderr, "Warning: cannot expand the organizations of %s: %v\n", username, err)
+		}
+
+		for _, org := range orgs {
+			sources = append(sources, github.Source{Kind: github.SourceOrg, Login: org})
+		}
+	}
 
-	var orgs []string
 	if orgString != "" {
-		orgs = strings.Split(orgString, ",")
+		for _, org := range strings.Split(orgString, ",") {
+			sources = append(sources, github.Source{Kind: github.SourceOrg, Login: org})
+		}
+	}
+
+	if *ownerPtr != "" {
+		for _, owner := range strings.Split(*ownerPtr, ",") {
+			sources = append(sources, github.Source{Kind: github.SourceOwner, Login: owner})
+		}
+	}
+
+	if fromFile != "" {
+		fileSources, err := config.LoadSources(fromFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading sources file: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		sources = append(sources, fileSources...)
+	}
+
+	// Names are enriched with a query per repository, as a source of their own
+	var names []string
+	if *stdinPtr {
+		names, err = config.ReadNames(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading repositories from stdin: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		sources = append(sources, github.Source{Kind: github.SourceNames, Login: "stdin"})
 	}
 
-	// Print help if orgs and username are not specified
-	if username == "" && len(orgs) == 0 {
-		fmt.Println("Usage: gh list-repos [-username <username>] [-orgs <org1,org2,...>] [-no-archived] [-no-fork]")
-		fmt.Println("\nAt least one of --username or --orgs must be provided")
+	// Fetch sources listed more than once (e.g. in -orgs and -from-file) a single time
+	seenSources := make(map[github.Source]bool)
+	sources = slices.DeleteFunc(sources, func(source github.Source) bool {
+		key := source
+		if *dedupeCaseInsensitivePtr {
+			// GitHub logins are case-insensitive, Acme and acme list the same repositories
+			key.Login = strings.ToLower(key.Login)
+		}
+
+		duplicate := seenSources[key]
+		seenSources[key] = true
+		return duplicate
+	})
+
+	// Print help if no sources are specified
+	if len(sources) == 0 {
+		fmt.Println("Usage: gh list-repos [-username <username>] [-orgs <org1,org2,...>] [-owner <login,...>] [-from-file <path>] [-stdin] [flags]")
+		fmt.Println("       gh list-repos clone [-dir <path>] [-bare] [-depth <n>] <owner/name>...")
+		fmt.Println("       gh list-repos open [-host <host>] <owner/name>...")
+		fmt.Println("       gh list-repos preview [-readme-lines <n>] [-detect-rename] <owner/name>")
+		fmt.Println("\nAt least one of --username, --orgs, --owner, --from-file or --stdin must be provided")
 		flag.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+
+	if urlType != "https" && urlType != "ssh" {
+		fmt.Fprintf(os.Stderr, "Invalid -url-type %q, expected https or ssh\n", urlType)
+		os.Exit(exitUsage)
+	}
+
+	// Write results to stdout unless an output file is provided
+	var out io.Writer = os.Stdout
+	if outputPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		outputFile, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		defer outputFile.Close()
+
+		out = outputFile
+	}
+
+	// Entries are NUL terminated last, so the postprocessed lines are too
+	if *print0Ptr {
+		if format == "json" || format == "ndjson" {
+			fmt.Fprintf(os.Stderr, "-print0 doesn't apply to -format %s\n", format)
+			os.Exit(exitUsage)
+		}
+
+		out = output.NewNulWriter(out)
+	}
+
+	// Lines are postprocessed in parallel, bounded by the number of CPUs, or all by the same command
+	var postprocessor io.WriteCloser
+	if *postprocessStreamPtr && *postprocessPtr == "" {
+		fmt.Fprintln(os.Stderr, "-postprocess-stream only applies to -postprocess")
+		os.Exit(exitUsage)
+	}
+
+	if *postprocessPtr != "" {
+		var err error
+		if *postprocessStreamPtr {
+			postprocessor, err = output.NewStreamPostprocessor(out, *postprocessPtr)
+		} else {
+			postprocessor, err = output.NewPostprocessor(out, *postprocessPtr, runtime.NumCPU())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -postprocess: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		out = postprocessor
+	}
+
+	if *jqPtr != "" {
+		if format != "json" {
+			fmt.Fprintln(os.Stderr, "-jq only applies to -format json")
+			os.Exit(exitUsage)
+		}
+
+		if err := output.ValidateJQ(*jqPtr); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -jq: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	// NO_COLOR (https://no-color.org) disables the colors when set to any value
+	colorTopics := *colorTopicsByHashPtr && !*noColorPtr && os.Getenv("NO_COLOR") == ""
+
+	lineOpts := github.LineOptions{ShowURL: showURL, URLType: urlType, ShowBranch: *showBranchPtr, ShowPermission: *showPermissionPtr, ShowSize: *showSizePtr, ShowLicense: *showLicensePtr, ShowLanguage: *showLanguagePtr, ShowCounts: *showCountsPtr, ShowRelease: *showReleasePtr, ShowUpdated: *showUpdatedPtr, SortTopicsByFrequency: *sortTopicsByFrequencyPtr, NoPadding: *noPaddingPtr, Compact: *compactPtr, ColorTopics: colorTopics}
+
+	formatOpts := output.Options{
+		Line:       lineOpts,
+		SplitOwner: *splitOwnerPtr,
+		Host:       *hostPtr,
+		Pretty:     *prettyPtr,
+		JQ:         *jqPtr,
+
+		IncludeArchivedInClone: *includeArchivedInClonePtr,
+		AllowDuplicates:        *allowDuplicatesPtr,
+		DedupeCaseInsensitive:  *dedupeCaseInsensitivePtr,
+		AnnotateSource:         *annotateSourcePtr,
 	}
 
-	// Channel to send repository lines to
-	repoLinesChannel := make(chan string)
+	writer, err := output.New(format, out, formatOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -format: %v\n", err)
+		os.Exit(exitUsage)
+	}
 
-	// Main wait group for all data sources
-	var wg sync.WaitGroup
+	if *outputTemplatePtr != "" && *templateFilePtr != "" {
+		fmt.Fprintln(os.Stderr, "-output-template and -template-file are mutually exclusive")
+		os.Exit(exitUsage)
+	}
 
-	if username != "" || len(orgs) > 0 {
-		wg.Add(1)
+	if *outputTemplatePtr != "" {
+		writer, err = output.NewTemplateWriter(out, *outputTemplatePtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -output-template: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
 
-		// Goroutine to fetch and stream repositories from GitHub API
-		go func() {
-			// Decrement main wg when this goroutine finishes
-			defer wg.Done()
+	if *templateFilePtr != "" {
+		writer, err = output.NewTemplateFileWriter(out, *templateFilePtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -template-file: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
 
-			// Wait group for user and organization fetches to run in parallel
-			var fetchWG sync.WaitGroup
+	if groupBySource && parallelSourcesOrdered {
+		fmt.Fprintln(os.Stderr, "-group-by-source and -parallel-sources-ordered are mutually exclusive")
+		os.Exit(exitUsage)
+	}
+
+	if *groupByPtr != "" {
+		if *outputTemplatePtr != "" || *templateFilePtr != "" {
+			fmt.Fprintln(os.Stderr, "-group-by can't be combined with -output-template or -template-file")
+			os.Exit(exitUsage)
+		}
 
-			// Get user repositories if username is provided (in parallel)
-			if username != "" {
-				fetchWG.Add(1)
+		if groupBySource || parallelSourcesOrdered {
+			fmt.Fprintln(os.Stderr, "-group-by can't be combined with -group-by-source or -parallel-sources-ordered")
+			os.Exit(exitUsage)
+		}
+
+		writer, err = output.NewGroupWriter(out, *groupByPtr, format, formatOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -group-by: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	// The menu is a fallback for terminals without fzf, pipes get the plain list
+	if *interactivePtr && outputPath == "" && term.IsTerminal(os.Stdout) && term.IsTerminal(os.Stdin) {
+		writer = &interactiveWriter{in: os.Stdin, menu: os.Stderr, out: os.Stdout, lineOpts: lineOpts}
+	}
+
+	if *excludeArchivedFromCountPtr && !*countOnlyPtr && !*summaryPtr {
+		fmt.Fprintln(os.Stderr, "-exclude-archived-from-count only applies to -count-only and -summary")
+		os.Exit(exitUsage)
+	}
+
+	if *countOnlyPtr {
+		writer = output.NewCountWriter(out, sources, *excludeArchivedFromCountPtr)
+	}
+
+	if *countByOwnerPtr {
+		writer = output.NewOwnerCountWriter(out)
+	}
 
-				// Launch new goroutine for username
-				go func() {
-					defer fetchWG.Done()
+	if *languageStatsPtr {
+		writer = output.NewLanguageStatsWriter(out, sources)
+	}
+
+	if *topPtr < 0 || (*topPtr > 0 && !*topicCloudPtr) {
+		fmt.Fprintln(os.Stderr, "-top must be positive and only applies to -topic-cloud")
+		os.Exit(exitUsage)
+	}
+
+	if *topicCloudPtr {
+		writer = output.NewTopicCloudWriter(out, *topPtr)
+	}
+
+	if *topicsExpandPtr {
+		writer = output.NewTopicsExpandWriter(out)
+	}
+
+	if *topicsAsColumnsPtr {
+		writer = output.NewTopicColumnsWriter(out, *maxTopicsPtr)
+	}
+
+	if *summaryPtr {
+		writer = output.NewSummaryWriter(out, sources, *excludeArchivedFromCountPtr)
+	}
+
+	if *reversePtr && *sortPtr == "" {
+		fmt.Fprintln(os.Stderr, "-reverse only applies to -sort")
+		os.Exit(exitUsage)
+	}
+
+	// -recent is -sort pushed keeping only the first repositories
+	sortKey := *sortPtr
+	if *recentPtr != 0 {
+		if *recentPtr < 0 || sortKey != "" {
+			fmt.Fprintln(os.Stderr, "-recent must be positive and can't be combined with -sort")
+			os.Exit(exitUsage)
+		}
+
+		sortKey = "pushed"
+	}
 
-					err := github.ProcessUserRepositories(username, noArchived, noFork, repoLinesChannel)
-					if err != nil {
-						log.Printf("Error getting user repositories for %s: %v", username, err)
+	if *deterministicPtr {
+		if sortKey != "" && sortKey != "name" {
+			fmt.Fprintln(os.Stderr, "-deterministic sorts by name, it can't be combined with another -sort or -recent")
+			os.Exit(exitUsage)
+		}
+
+		sortKey = "name"
+	}
+
+	// Sorting needs the complete result set, so this wraps whatever writer was chosen
+	if sortKey != "" {
+		if groupBySource || parallelSourcesOrdered {
+			fmt.Fprintln(os.Stderr, "-sort and -recent can't be combined with -group-by-source or -parallel-sources-ordered")
+			os.Exit(exitUsage)
+		}
+
+		writer, err = output.NewSortWriter(writer, sortKey, *reversePtr, *recentPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -sort: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	// Forks are compared against the complete result set, so this wraps whatever writer was chosen
+	if *dedupeForksPtr || *preferForksPtr {
+		if *dedupeForksPtr && *preferForksPtr {
+			fmt.Fprintln(os.Stderr, "-dedupe-forks and -prefer-forks are mutually exclusive")
+			os.Exit(exitUsage)
+		}
+
+		if *preferForksPtr && noFork {
+			fmt.Fprintln(os.Stderr, "-prefer-forks can't be combined with -no-fork")
+			os.Exit(exitUsage)
+		}
+
+		writer = output.NewForkDedupeWriter(writer, *preferForksPtr)
+	}
+
+	if client == nil {
+		newClient()
+	}
+
+	if *validatePtr {
+		if dryRun {
+			fmt.Fprintln(os.Stderr, "-validate and -dry-run are mutually exclusive")
+			os.Exit(exitUsage)
+		}
+
+		os.Exit(runValidate(client, github.ClientOptions{AuthToken: token, Host: *hostPtr, Headers: requestHeaders}, sources))
+	}
+
+	opts := github.Options{NoArchived: noArchived, NoFork: noFork, Fields: fields, Client: client, PageSize: pageSize, Retries: *retriesPtr, NormalizeTopics: *normalizeTopicsPtr}
+
+	if *noTemplatesPtr && *onlyTemplatesPtr {
+		fmt.Fprintln(os.Stderr, "-no-templates and -only-templates are mutually exclusive")
+		os.Exit(exitUsage)
+	}
+
+	if *noTemplatesPtr {
+		opts.Filters = append(opts.Filters, github.NoTemplates)
+	}
+
+	if *onlyTemplatesPtr {
+		opts.Filters = append(opts.Filters, github.OnlyTemplates)
+	}
+
+	if *defaultBranchPtr != "" {
+		opts.Filters = append(opts.Filters, github.DefaultBranch(*defaultBranchPtr))
+	}
+
+	if *noDisabledPtr {
+		opts.Filters = append(opts.Filters, github.NoDisabled)
+	}
+
+	if *noTopicsPtr && (*hasTopicsPtr || *minTopicsPtr > 0) {
+		fmt.Fprintln(os.Stderr, "-no-topics can't be combined with -has-topics or -min-topics")
+		os.Exit(exitUsage)
+	}
+
+	if *minTopicsPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -min-topics %d, it can't be negative\n", *minTopicsPtr)
+		os.Exit(exitUsage)
+	}
+
+	if *noTopicsPtr {
+		opts.Filters = append(opts.Filters, github.NoTopics)
+	}
+
+	if *hasTopicsPtr {
+		opts.Filters = append(opts.Filters, github.MinTopics(1))
+	}
+
+	if *minTopicsPtr > 0 {
+		opts.Filters = append(opts.Filters, github.MinTopics(*minTopicsPtr))
+	}
+
+	if *nameFilterPtr != "" || *matchDescriptionPtr != "" {
+		match, err := github.Match(*nameFilterPtr, *matchDescriptionPtr, *matchModePtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -match-mode: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, match)
+	}
+
+	if *noEmptyPtr {
+		opts.Filters = append(opts.Filters, github.NoEmpty)
+	}
+
+	if *issuesEnabledPtr != "" {
+		enabled, err := strconv.ParseBool(*issuesEnabledPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -issues-enabled %q, expected true or false\n", *issuesEnabledPtr)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, github.IssuesEnabled(enabled))
+	}
+
+	if *wikiEnabledPtr != "" {
+		enabled, err := strconv.ParseBool(*wikiEnabledPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -wiki-enabled %q, expected true or false\n", *wikiEnabledPtr)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, github.WikiEnabled(enabled))
+	}
+
+	if *largerThanPtr != "" {
+		size, err := utils.ParseSize(*largerThanPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -larger-than: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, github.LargerThan(size))
+	}
+
+	if *smallerThanPtr != "" {
+		size, err := utils.ParseSize(*smallerThanPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -smaller-than: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, github.SmallerThan(size))
+	}
+
+	if *releasedSincePtr != "" {
+		age, err := utils.ParseDuration(*releasedSincePtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -released-since: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, github.ReleasedSince(time.Now().Add(-age), *includeNoReleasePtr))
+	}
+
+	if *licensePtr != "" {
+		opts.Filters = append(opts.Filters, github.Licenses(strings.Split(*licensePtr, ",")))
+	}
+
+	if *minPermissionPtr != "" {
+		minPermission, err := github.MinPermission(*minPermissionPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -min-permission: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, minPermission)
+	}
+
+	// Exclusions run last so they always win over the inclusion filters
+	if *excludePtr != "" {
+		exclude, err := github.Exclude(strings.Split(*excludePtr, ","))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -exclude: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, exclude)
+	}
+
+	// The query runs after every other filter, narrowing down what is left
+	if *queryPtr != "" {
+		query, err := github.Query(*queryPtr, queryFields)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -query-fields: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		opts.Filters = append(opts.Filters, query)
+	}
+
+	if *collaboratorPtr != "" {
+		opts.Collaborator = github.NewCollaboratorFilter(*collaboratorPtr)
+	}
+
+	opts.BatchOrgs = *batchOrgsPtr
+
+	if *sourceTimeoutPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -source-timeout %s, it can't be negative\n", *sourceTimeoutPtr)
+		os.Exit(exitUsage)
+	}
+	opts.SourceTimeout = *sourceTimeoutPtr
+
+	if *retryLogPtr != "" {
+		opts.RetryLog = github.NewRetryLog(*retryLogPtr)
+	}
+
+	if *limitPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -limit %d, it can't be negative\n", *limitPtr)
+		os.Exit(exitUsage)
+	}
+	opts.Limit = *limitPtr
+
+	if *limitPerSourcePtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -limit-per-source %d, it can't be negative\n", *limitPerSourcePtr)
+		os.Exit(exitUsage)
+	}
+	opts.LimitPerSource = *limitPerSourcePtr
+	opts.MaxTopics = *maxTopicsPtr
+	opts.Names = names
+	opts.DetectRename = *detectRenamePtr
+	opts.FirstPageOnly = *firstPageOnlyPtr
+
+	if *maxPagesPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -max-pages %d, it can't be negative\n", *maxPagesPtr)
+		os.Exit(exitUsage)
+	}
+	opts.MaxPages = *maxPagesPtr
+
+	if *maxConcurrencyPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -max-concurrency %d, it can't be negative\n", *maxConcurrencyPtr)
+		os.Exit(exitUsage)
+	}
+	opts.MaxConcurrency = *maxConcurrencyPtr
+	if *deterministicPtr {
+		opts.MaxConcurrency = 1
+	}
+
+	if showRateLimit {
+		opts.RateLimit = &github.RateLimitUsage{}
+	}
+
+	// The cache is only valid for the options changing what the API returns
+	if *sinceCachePtr && !dryRun {
+		key := fmt.Sprintf("host=%s fields=%s max-topics=%d no-archived=%t no-fork=%t", *hostPtr, strings.Join(fields, ","), *maxTopicsPtr, noArchived, noFork)
+		opts.SinceCache, err = github.LoadSinceCache(filepath.Join(appDir, "since-cache.json"), key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading since cache: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	if *resumeFilePtr != "" && !dryRun {
+		if opts.SinceCache != nil {
+			fmt.Fprintln(os.Stderr, "-resume-file and -since-cache are mutually exclusive")
+			os.Exit(exitUsage)
+		}
+
+		// The skipped pages were printed with the filters of the previous run
+		filters := fmt.Sprintf("no-templates=%t only-templates=%t default-branch=%s no-disabled=%t no-empty=%t "+
+			"no-topics=%t has-topics=%t min-topics=%d name-filter=%s match-description=%s match-mode=%s "+
+			"issues-enabled=%s wiki-enabled=%s larger-than=%s smaller-than=%s released-since=%s include-no-release=%t "+
+			"license=%s min-permission=%s exclude=%s query=%s query-fields=%s collaborator=%s",
+			*noTemplatesPtr, *onlyTemplatesPtr, *defaultBranchPtr, *noDisabledPtr, *noEmptyPtr,
+			*noTopicsPtr, *hasTopicsPtr, *minTopicsPtr, *nameFilterPtr, *matchDescriptionPtr, *matchModePtr,
+			*issuesEnabledPtr, *wikiEnabledPtr, *largerThanPtr, *smallerThanPtr, *releasedSincePtr, *includeNoReleasePtr,
+			*licensePtr, *minPermissionPtr, *excludePtr, *queryPtr, *queryFieldsPtr, *collaboratorPtr)
+		opts.Resume, err = github.LoadResumeState(*resumeFilePtr, filters)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading resume file: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	var progressBar *progress.Bar
+	if *progressPtr {
+		progressBar = progress.New(os.Stderr, len(sources))
+		opts.Progress = progressBar
+	}
+
+	// The TotalCount of the previous run is the progress estimate until the first page arrives
+	var totals *progress.Totals
+	if !dryRun {
+		key := fmt.Sprintf("host=%s no-archived=%t no-fork=%t", *hostPtr, noArchived, noFork)
+		totals, err = progress.LoadTotals(filepath.Join(appDir, "totals.json"), key)
+		if err != nil {
+			// only an estimate, not worth failing the run for
+			log.Printf("Warning: ignoring the previous totals: %v", err)
+		} else {
+			if progressBar != nil {
+				for _, source := range sources {
+					if total, found := totals.Get(source); found {
+						progressBar.SetTotal(source, total)
 					}
-				}()
+				}
 			}
 
-			// Get organization repositories if orgs are provided
-			if len(orgs) > 0 {
-				for _, org := range orgs {
-					fetchWG.Add(1)
-
-					// Launch new goroutine for each org
-					go func(currentOrg string) {
-						// Decrement fetch wg when this org goroutine finishes
-						defer fetchWG.Done()
-
-						err := github.ProcessOrgRepositories(currentOrg, noArchived, noFork, repoLinesChannel)
-						if err != nil {
-							// Log error but continue with other orgs
-							log.Printf("Warning: Error getting organization repositories for %s: %v", currentOrg, err)
-						}
-						// Pass the current org value to the goroutine
-					}(org)
-				}
+			// -first-page-only clamps the totals to its first pages, they would be a wrong estimate for the next full run
+			if !opts.FirstPageOnly {
+				opts.Progress = totals.Track(opts.Progress)
 			}
+		}
+	}
+
+	// Errors of the failed sources, reported once every source is done
+	var failures []sourceFailure
+	var failuresMutex sync.Mutex
+
+	// Watch mode keeps fetching until interrupted, printing only what changed
+	if *watchPtr {
+		if *intervalPtr <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid -interval %s, it must be positive\n", *intervalPtr)
+			os.Exit(exitUsage)
+		}
+
+		// the combined progress bar only makes sense for a single run
+		opts.Progress = nil
+		os.Exit(runWatch(sources, opts, lineOpts, out, *intervalPtr))
+	}
+
+	if *bufferPtr < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -buffer %d, it can't be negative\n", *bufferPtr)
+		os.Exit(exitUsage)
+	}
+
+	resultChannel := fetchSources(sources, opts, *bufferPtr, parallelSourcesOrdered, func(source github.Source, err error) {
+		// every source shares the token, so there is no point in waiting for the others
+		if *failFastOnAuthPtr && errors.Is(err, github.ErrUnauthorized) {
+			log.Printf("Error getting repositories for %s: %v", source, err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Run `gh auth login` (or `gh auth refresh`) or pass a valid token with -token, GH_TOKEN or GITHUB_TOKEN.")
+			os.Exit(exitTotalFailure)
+		}
+
+		if strict {
+			log.Printf("Error getting repositories for %s: %v", source, err)
+			fmt.Fprintf(os.Stderr, "Error getting repositories for %s: %v\n", source, err)
+			os.Exit(exitTotalFailure)
+		}
+
+		// Log error but continue with other sources
+		log.Printf("Warning: Error getting repositories for %s: %v", source, err)
+		failuresMutex.Lock()
+		failures = append(failures, sourceFailure{Source: source, Err: err})
+		failuresMutex.Unlock()
+	})
 
-			// Wait for all user and org goroutines to complete
-			fetchWG.Wait()
-		}()
+	write := func(result github.Result) {
+		if err := writer.Write(result); err != nil {
+			log.Printf("Error writing %s: %v", result.Repository.NameWithOwner, err)
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", result.Repository.NameWithOwner, err)
+		}
 	}
 
-	// Goroutine to close the channel when all data source workers are done
-	go func() {
-		// Wait for the API goroutine (if active)
-		wg.Wait()
-		close(repoLinesChannel)
-	}()
+	// Repositories of each source when they are printed grouped by source
+	groups := make(map[github.Source][]github.Result)
+	// Number of repositories emitted by each source, to report the empty ones
+	emitted := make(map[github.Source]int)
+	seenRepos := make(seenRepositories)
+
+	// Stream results from the channel to standard output (e.g., fzf) or the output file
+	for result := range resultChannel {
+		// A source is complete, print its block
+		if result.Done {
+			for _, buffered := range groups[result.Source] {
+				write(buffered)
+			}
+			delete(groups, result.Source)
+			continue
+		}
+
+		emitted[result.Source]++
+
+		// Still counted, the source isn't reported as empty
+		if *dedupeCaseInsensitivePtr && seenRepos.duplicate(result.Repository) {
+			continue
+		}
+
+		if groupBySource || parallelSourcesOrdered {
+			groups[result.Source] = append(groups[result.Source], result)
+			continue
+		}
+
+		write(result)
+	}
+
+	// Every source is done once the channel is closed
+	if progressBar != nil {
+		progressBar.Finish()
+	}
+
+	// Print each source in the order they were specified, keeping the API order within a source
+	if groupBySource {
+		for _, source := range sources {
+			for _, result := range groups[source] {
+				write(result)
+			}
+		}
+	}
+
+	// Formats like json only write once all repositories were received
+	if !dryRun {
+		if err := writer.Flush(); err != nil {
+			log.Printf("Error writing results: %v", err)
+		}
+	}
+
+	if postprocessor != nil {
+		if err := postprocessor.Close(); err != nil {
+			log.Printf("Error postprocessing results: %v", err)
+		}
+	}
+
+	if totals != nil {
+		if err := totals.Save(); err != nil {
+			log.Printf("Error writing totals: %v", err)
+		}
+	}
+
+	if opts.SinceCache != nil {
+		if err := opts.SinceCache.Save(); err != nil {
+			log.Printf("Error writing since cache: %v", err)
+		}
+	}
+
+	if opts.RetryLog != nil {
+		if err := opts.RetryLog.Close(); err != nil {
+			log.Printf("Error closing retry log: %v", err)
+		}
+	}
+
+	if opts.RateLimit != nil {
+		rateLimit := opts.RateLimit.Summary()
+		log.Printf("Rate limit: cost %d, remaining %d/%d, resets at %s", rateLimit.Cost, rateLimit.Remaining, rateLimit.Limit, rateLimit.ResetAt)
+		fmt.Fprintf(os.Stderr, "GraphQL rate limit: used %d points, %d of %d remaining, resets at %s\n",
+			rateLimit.Cost, rateLimit.Remaining, rateLimit.Limit, rateLimit.ResetAt.Local().Format(time.DateTime))
+	}
+
+	// Sources without any repository (matching the filters) are normal when scanning many organizations,
+	// they are only reported on request. Once -limit is reached the remaining sources had no chance.
+	limitReached := opts.Limit > 0 && len(emitted) > 0 && sumCounts(emitted) >= opts.Limit
+	if !*skipEmptySourcesPtr && !dryRun && !limitReached {
+		var empty []github.Source
+		for _, source := range sources {
+			failed := slices.ContainsFunc(failures, func(failure sourceFailure) bool { return failure.Source == source })
+			if emitted[source] == 0 && !failed {
+				empty = append(empty, source)
+			}
+		}
+
+		if len(empty) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d of %d sources listed no repositories (matching the filters):\n", len(empty), len(sources))
+			for _, source := range empty {
+				log.Printf("Warning: %s listed no repositories", source)
+				fmt.Fprintf(os.Stderr, "  %s\n", source)
+			}
+		}
+	}
+
+	// Repositories of the successful sources were printed, but scripts need to know about the failed ones
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d sources failed:\n", len(failures), len(sources))
+		for _, failure := range failures {
+			// a typo in a login is the most common failure, point at it explicitly
+			if errors.Is(failure.Err, github.ErrSourceNotFound) {
+				fmt.Fprintf(os.Stderr, "  %s: not found, check the login and that your token can access it\n", failure.Source)
+				continue
+			}
+
+			if errors.Is(failure.Err, github.ErrSourceTimeout) {
+				fmt.Fprintf(os.Stderr, "  %s: partial, %v\n", failure.Source, failure.Err)
+				continue
+			}
+
+			if errors.Is(failure.Err, github.ErrSourceForbidden) {
+				fmt.Fprintf(os.Stderr, "  %s: suspended or blocked for your token, skipped (see the log for details)\n", failure.Source)
+				continue
+			}
+
+			fmt.Fprintf(os.Stderr, "  %s: %v\n", failure.Source, failure.Err)
+		}
 
-	var repos []string
-	// Stream results from the channel to standard output (e.g., fzf)
-	for repoName := range repoLinesChannel {
-		fmt.Println(repoName)
-		repos = append(repos, repoName)
+		if len(failures) == len(sources) {
+			os.Exit(exitTotalFailure)
+		}
+		os.Exit(exitPartialFailure)
 	}
 
 	// if isFileCacheEnabled {
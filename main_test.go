@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+	"github.com/arielschiavoni/gh-list-repos/internal/output"
+)
+
+func TestMixedCaseDuplicatesAreEmittedOnce(t *testing.T) {
+	owner := github.Source{Kind: github.SourceOwner, Login: "acme"}
+	org := github.Source{Kind: github.SourceOrg, Login: "Acme"}
+	results := []github.Result{
+		{Source: owner, Repository: github.Repository{NameWithOwner: "Acme/Repo"}},
+		{Source: org, Repository: github.Repository{NameWithOwner: "acme/repo"}},
+		{Source: owner, Repository: github.Repository{NameWithOwner: "acme/other"}},
+		{Source: org, Repository: github.Repository{NameWithOwner: "ACME/REPO"}},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "line", want: "Acme/Repo\nacme/other\n"},
+		{format: "ndjson", want: `{"name_with_owner":"Acme/Repo"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buf strings.Builder
+			writer, err := output.New(test.format, &buf, output.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			seen := make(seenRepositories)
+			for _, result := range results {
+				if seen.duplicate(result.Repository) {
+					continue
+				}
+
+				if err := writer.Write(result); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+				t.Errorf("printed %d repositories, want 2:\n%s", lines, buf.String())
+			}
+
+			if !strings.HasPrefix(buf.String(), test.want) {
+				t.Errorf("printed\n%s\nwant the first seen casing\n%s", buf.String(), test.want)
+			}
+		})
+	}
+}
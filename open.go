@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/browser"
+)
+
+// maxOpenRepos avoids spawning dozens of browser tabs when many repositories are selected
+const maxOpenRepos = 10
+
+// runOpen implements "gh list-repos open owner/name [owner/name...]" and returns the exit code
+func runOpen(args []string) int {
+	defaultHost, _ := auth.DefaultHost()
+
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	hostPtr := fs.String("host", defaultHost, "GitHub host used to build the repository URL")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gh list-repos open [-host <host>] <owner/name>...")
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	names := fs.Args()
+	if len(names) > maxOpenRepos {
+		fmt.Fprintf(os.Stderr, "Opening only the first %d of %d repositories\n", maxOpenRepos, len(names))
+		names = names[:maxOpenRepos]
+	}
+
+	b := browser.New("", os.Stdout, os.Stderr)
+
+	failed := 0
+	for _, arg := range names {
+		// accept whole lines selected in fzf, the repository is always the first field
+		fields := strings.Fields(arg)
+		if len(fields) == 0 {
+			continue
+		}
+
+		url := fmt.Sprintf("https://%s/%s", *hostPtr, fields[0])
+		log.Printf("Opening %s", url)
+
+		if err := b.Browse(url); err != nil {
+			log.Printf("Error opening %s: %v", url, err)
+			fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", url, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
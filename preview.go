@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// runPreview implements "gh list-repos preview owner/name", meant for the fzf preview window
+func runPreview(args []string) int {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	tokenPtr := fs.String("token", "", "GitHub token used instead of the gh authentication (default GH_TOKEN or GITHUB_TOKEN)")
+	detectRenamePtr := fs.Bool("detect-rename", false, "Warns with an \"old -> new\" line when the repository was renamed or transferred")
+	readmeLinesPtr := fs.Int("readme-lines", 0, "Prints the first given number of lines of the README too, at the cost of a second query (0 skips it)")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gh list-repos preview [-readme-lines <n>] [-detect-rename] <owner/name>")
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	// accept the whole line selected in fzf, the repository is always the first field
+	fields := strings.Fields(strings.Join(fs.Args(), " "))
+	if len(fields) == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	token := *tokenPtr
+	if token == "" {
+		token = github.TokenFromEnv()
+	}
+
+	client, err := github.NewClient(github.ClientOptions{AuthToken: token})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating GitHub client: %v\n", err)
+		return 1
+	}
+
+	repo, err := github.GetRepository(client, fields[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting %s: %v\n", fields[0], err)
+		return 1
+	}
+
+	if *detectRenamePtr && github.Renamed(fields[0], repo) {
+		fmt.Fprintf(os.Stderr, "Warning: %s -> %s, renamed or transferred\n", fields[0], repo.NameWithOwner)
+	}
+
+	writePreview(os.Stdout, repo)
+
+	if *readmeLinesPtr > 0 {
+		// a missing README shouldn't fail the preview of the repository
+		readme, truncated, err := github.GetReadme(client, repo.NameWithOwner, *readmeLinesPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting the README of %s: %v\n", repo.NameWithOwner, err)
+			return 0
+		}
+
+		writeReadme(os.Stdout, readme, truncated)
+	}
+
+	return 0
+}
+
+// writeReadme prints the first lines of the README after the details
+func writeReadme(w io.Writer, readme string, truncated bool) {
+	fmt.Fprintln(w)
+
+	if readme == "" {
+		fmt.Fprintln(w, "No README")
+		return
+	}
+
+	fmt.Fprintln(w, readme)
+	if truncated {
+		fmt.Fprintln(w, "...")
+	}
+}
+
+// writePreview prints the details of a repository as readable text
+func writePreview(w io.Writer, repo github.Repository) {
+	fmt.Fprintln(w, repo.NameWithOwner)
+	if repo.Description != "" {
+		fmt.Fprintln(w, repo.Description)
+	}
+	fmt.Fprintln(w)
+
+	var markers []string
+	if repo.IsArchived {
+		markers = append(markers, "archived (read-only)")
+	}
+	if repo.IsFork {
+		markers = append(markers, "fork")
+	}
+	if repo.IsTemplate {
+		markers = append(markers, "template")
+	}
+	if len(markers) > 0 {
+		fmt.Fprintln(w, strings.Join(markers, " | "))
+	}
+
+	if topics := repo.Topics(); len(topics) > 0 {
+		fmt.Fprintf(w, "Topics:      %s\n", strings.Join(topics, ", "))
+	}
+
+	if branch := repo.DefaultBranch(); branch != "" {
+		fmt.Fprintf(w, "Branch:      %s\n", branch)
+	}
+
+	if commit := repo.LastCommit(); commit != nil {
+		fmt.Fprintf(w, "Last commit: %s by %s\n", commit.CommittedDate.Local().Format("2006-01-02 15:04"), commit.Author.Name)
+	} else {
+		fmt.Fprintln(w, "Last commit: none (empty repository)")
+	}
+
+	fmt.Fprintf(w, "URL:         %s\n", repo.URL)
+
+	// users and organizations expose the same profile fields
+	if owner := repo.OwnerProfile; owner != nil {
+		kind := "user"
+		if owner.Typename == "Organization" {
+			kind = "organization"
+		}
+
+		fmt.Fprintf(w, "Owner:       %s (%s) %s\n", owner.Login, kind, owner.URL)
+		if owner.AvatarURL != "" {
+			fmt.Fprintf(w, "Avatar:      %s\n", owner.AvatarURL)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// runValidate prints whether the token can list the private repositories of the sources and
+// returns the exit code: exitOK when it can (or its scopes can't be read), exitTotalFailure otherwise
+func runValidate(client github.GraphQLClient, clientOpts github.ClientOptions, sources []github.Source) int {
+	info, err := github.GetTokenInfo(client, clientOpts)
+	if err != nil {
+		log.Printf("Error validating the token: %v", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Println("FAIL: the token can't be used")
+		return exitTotalFailure
+	}
+
+	// fine-grained and app tokens only have permissions on selected repositories, which the API doesn't report
+	if info.Scopes == nil {
+		fmt.Printf("Token of %s, its scopes can't be read (fine-grained or GitHub App token)\n", info.Login)
+		fmt.Println("PASS: authenticated, check the repository access of the token to list private repositories")
+		return exitOK
+	}
+
+	fmt.Printf("Token of %s, scopes: %s\n", info.Login, strings.Join(info.Scopes, ", "))
+
+	passed := true
+	for _, check := range github.CheckScopes(info, sources) {
+		status := "ok     "
+		if !check.Granted {
+			status = "missing"
+			passed = false
+		}
+
+		fmt.Printf("  %s %s, for the %s\n", status, check.Scope, check.Reason)
+	}
+
+	if !passed {
+		fmt.Println("FAIL: only the public repositories would be listed, run `gh auth refresh -s repo,read:org` or use a token with the missing scopes")
+		return exitTotalFailure
+	}
+
+	fmt.Println("PASS: the token can list the private repositories of every source")
+	return exitOK
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/arielschiavoni/gh-list-repos/internal/github"
+)
+
+// watchedRepository is a repository seen by the previous watch iteration
+type watchedRepository struct {
+	source github.Source
+	line   string
+}
+
+// runWatch re-fetches the sources every interval and prints the repositories added
+// ("+ " prefix) or removed ("- " prefix) since the previous iteration. The first
+// iteration prints the full list. It stops on SIGINT and returns the exit code.
+func runWatch(sources []github.Source, opts github.Options, lineOpts github.LineOptions, out io.Writer, interval time.Duration) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var previous map[string]watchedRepository
+
+	for iteration := 1; ; iteration++ {
+		log.Printf("Watch iteration %d", iteration)
+
+		// a failed source keeps its previous repositories instead of reporting them all as removed
+		var failed sync.Map
+		results := fetchSources(sources, opts, 0, false, func(source github.Source, err error) {
+			log.Printf("Warning: Error getting repositories for %s: %v", source, err)
+			fmt.Fprintf(os.Stderr, "Error getting repositories for %s: %v\n", source, err)
+			failed.Store(source, true)
+		})
+
+		current := make(map[string]watchedRepository)
+		var added []string
+
+		for result := range results {
+			name := result.Repository.NameWithOwner
+			if _, seen := current[name]; !seen {
+				added = append(added, name)
+			}
+			current[name] = watchedRepository{source: result.Source, line: result.Repository.Line(lineOpts)}
+		}
+
+		var removed []string
+		for name, repo := range previous {
+			if _, found := current[name]; found {
+				continue
+			}
+
+			if _, sourceFailed := failed.Load(repo.source); sourceFailed {
+				current[name] = repo
+				continue
+			}
+
+			removed = append(removed, name)
+		}
+		slices.Sort(removed)
+
+		for _, name := range added {
+			if previous == nil {
+				fmt.Fprintln(out, current[name].line)
+				continue
+			}
+
+			if _, found := previous[name]; !found {
+				fmt.Fprintf(out, "+ %s\n", current[name].line)
+			}
+		}
+
+		for _, name := range removed {
+			fmt.Fprintf(out, "- %s\n", previous[name].line)
+		}
+
+		previous = current
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Watch interrupted after %d iterations", iteration)
+			return exitOK
+		case <-time.After(interval):
+		}
+	}
+}